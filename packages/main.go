@@ -27,4 +27,17 @@ func main() {
 	fmt.Println("Learning packages")
 	fmt.Println(math.Floor(2.7), math.Ceil(2.7), math.Sqrt(17))
 	fmt.Println(strutil.Reverse("hello"))
+
+	// Reversing runes breaks characters made of several runes, strutil works on what a reader sees as characters.
+	family := "👨\u200d👩\u200d👧 noe\u0308l"
+	fmt.Println(strutil.ReverseRunes(family), strutil.Reverse(family))
+	fmt.Println(len(family), strutil.GraphemeCount(family), strutil.Width(family))
+	fmt.Println(strutil.Truncate("日本語のテキスト", 9, "…"))
+	fmt.Println(strutil.Wrap("Exported names start with a capital letter, unexported ones do not.", 24))
+	fmt.Printf("[%s][%s][%s]\n", strutil.PadLeft("日本", 6, ' '), strutil.PadRight("né", 6, '.'), strutil.PadCenter("go", 6, '*'))
+
+	for _, name := range []string{"parseHTTPRequest", "user_id", "Größe Änderung"} {
+		fmt.Println(strutil.CamelCase(name), strutil.PascalCase(name), strutil.SnakeCase(name), strutil.KebabCase(name))
+	}
+	fmt.Println(strutil.Slugify("Crème Brûlée: l'été à Paris!"))
 }
@@ -0,0 +1,96 @@
+package strutil
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Words splits s into words for the case conversions: at every character which is neither a letter, a mark nor a
+// digit, and where the case changes, so "parseHTTPRequest2", "parse_http_request2" and "Parse HTTP request2" all
+// give parse, HTTP/http and request2. Digits stay with the word before them. Letters without case, like the
+// Chinese or Japanese ones, do not split words.
+func Words(s string) []string {
+	runes := []rune(s)
+	var words []string
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+
+		if unicode.IsUpper(r) || unicode.IsTitle(r) {
+			previous := runes[i-1]
+			// "camelCase" splits before the upper case letter, and "HTTPRequest" before the last upper case letter
+			// of the acronym.
+			afterLower := unicode.IsLower(previous) || unicode.IsDigit(previous)
+			endsAcronym := unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if afterLower || endsAcronym {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+// capitalize writes word with its first letter in title case and the rest in lower case. Title case differs from
+// upper case for a few digraphs, e.g. "ǆ" becomes "ǅ" and not "Ǆ".
+func capitalize(builder *strings.Builder, word string) {
+	for i, r := range word {
+		if i == 0 {
+			builder.WriteRune(unicode.ToTitle(r))
+		} else {
+			builder.WriteRune(unicode.ToLower(r))
+		}
+	}
+}
+
+// CamelCase converts s to camelCase, e.g. "user_id" and "User ID" become "userId".
+func CamelCase(s string) string {
+	var builder strings.Builder
+	for i, word := range Words(s) {
+		if i == 0 {
+			builder.WriteString(strings.ToLower(word))
+		} else {
+			capitalize(&builder, word)
+		}
+	}
+	return builder.String()
+}
+
+// PascalCase converts s to PascalCase, e.g. "user_id" becomes "UserId".
+func PascalCase(s string) string {
+	var builder strings.Builder
+	for _, word := range Words(s) {
+		capitalize(&builder, word)
+	}
+	return builder.String()
+}
+
+// SnakeCase converts s to snake_case, e.g. "userID" becomes "user_id".
+func SnakeCase(s string) string {
+	return joinLower(Words(s), "_")
+}
+
+// KebabCase converts s to kebab-case, e.g. "userID" becomes "user-id".
+func KebabCase(s string) string {
+	return joinLower(Words(s), "-")
+}
+
+func joinLower(words []string, separator string) string {
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return strings.Join(words, separator)
+}
@@ -0,0 +1,61 @@
+package strutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		s    string
+		want []string
+	}{
+		{"", nil},
+		{"parseHTTPRequest2", []string{"parse", "HTTP", "Request2"}},
+		{"parse_http_request2", []string{"parse", "http", "request2"}},
+		{"Parse HTTP request2", []string{"Parse", "HTTP", "request2"}},
+		{"  --user--id--  ", []string{"user", "id"}},
+		{"userID", []string{"user", "ID"}},
+		{"version2Update", []string{"version2", "Update"}},
+		{"ÉtéÀParis", []string{"Été", "À", "Paris"}},
+		{"日本語テキスト", []string{"日本語テキスト"}},
+	}
+	for _, test := range tests {
+		if got := Words(test.s); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Words(%q) = %q, want %q", test.s, got, test.want)
+		}
+	}
+}
+
+func TestCaseConversions(t *testing.T) {
+	tests := []struct {
+		s      string
+		camel  string
+		pascal string
+		snake  string
+		kebab  string
+	}{
+		{"", "", "", "", ""},
+		{"user_id", "userId", "UserId", "user_id", "user-id"},
+		{"User ID", "userId", "UserId", "user_id", "user-id"},
+		{"userID", "userId", "UserId", "user_id", "user-id"},
+		{"parseHTTPRequest2", "parseHttpRequest2", "ParseHttpRequest2", "parse_http_request2", "parse-http-request2"},
+		{"crème brûlée", "crèmeBrûlée", "CrèmeBrûlée", "crème_brûlée", "crème-brûlée"},
+		// Title case differs from upper case for the digraphs.
+		{"a ǆungla", "aǅungla", "Aǅungla", "a_ǆungla", "a-ǆungla"},
+	}
+	for _, test := range tests {
+		if got := CamelCase(test.s); got != test.camel {
+			t.Errorf("CamelCase(%q) = %q, want %q", test.s, got, test.camel)
+		}
+		if got := PascalCase(test.s); got != test.pascal {
+			t.Errorf("PascalCase(%q) = %q, want %q", test.s, got, test.pascal)
+		}
+		if got := SnakeCase(test.s); got != test.snake {
+			t.Errorf("SnakeCase(%q) = %q, want %q", test.s, got, test.snake)
+		}
+		if got := KebabCase(test.s); got != test.kebab {
+			t.Errorf("KebabCase(%q) = %q, want %q", test.s, got, test.kebab)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package strutil
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// A grapheme cluster is what a reader sees as a single character, which can be made of several runes: "é" written
+// as "e" plus a combining accent, the family emoji "👨‍👩‍👧" (three emoji joined by zero width joiners), "👍🏽" (an
+// emoji with a skin tone modifier) or the flag "🇮🇳" (two regional indicators). Operating on runes splits these
+// apart, so Reverse, Truncate, Width and Wrap work on grapheme clusters instead.
+//
+// The segmentation follows the rules of Unicode Standard Annex #29 (extended grapheme clusters), with the
+// character properties approximated from the standard library's unicode tables, as the exact property tables are
+// not part of the standard library. Prepend characters and the Indic conjunct rules are not supported.
+
+type graphemeProperty int
+
+const (
+	otherProperty graphemeProperty = iota
+	crProperty
+	lfProperty
+	controlProperty
+	extendProperty
+	zwjProperty
+	regionalIndicatorProperty
+	spacingMarkProperty
+	hangulLProperty
+	hangulVProperty
+	hangulTProperty
+	hangulLVProperty
+	hangulLVTProperty
+	pictographicProperty
+)
+
+func propertyOf(r rune) graphemeProperty {
+	switch {
+	case r == '\r':
+		return crProperty
+	case r == '\n':
+		return lfProperty
+	case r == 0x200D:
+		return zwjProperty
+	case r == 0x200C, 0x1F3FB <= r && r <= 0x1F3FF, 0xE0020 <= r && r <= 0xE007F:
+		// Zero width non-joiner, emoji skin tone modifiers and emoji tag characters.
+		return extendProperty
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return extendProperty
+	case unicode.In(r, unicode.Cc, unicode.Cf, unicode.Zl, unicode.Zp):
+		return controlProperty
+	case unicode.Is(unicode.Mc, r):
+		return spacingMarkProperty
+	case 0x1F1E6 <= r && r <= 0x1F1FF:
+		return regionalIndicatorProperty
+	case 0x1100 <= r && r <= 0x115F, 0xA960 <= r && r <= 0xA97C:
+		return hangulLProperty
+	case 0x1160 <= r && r <= 0x11A7, 0xD7B0 <= r && r <= 0xD7C6:
+		return hangulVProperty
+	case 0x11A8 <= r && r <= 0x11FF, 0xD7CB <= r && r <= 0xD7FB:
+		return hangulTProperty
+	case 0xAC00 <= r && r <= 0xD7A3:
+		// Precomposed syllables come in blocks of 28, the first of every block has no trailing consonant.
+		if (r-0xAC00)%28 == 0 {
+			return hangulLVProperty
+		}
+		return hangulLVTProperty
+	case isPictographic(r):
+		return pictographicProperty
+	}
+	return otherProperty
+}
+
+// isPictographic approximates the Extended_Pictographic property with the blocks emoji are allocated in.
+func isPictographic(r rune) bool {
+	switch {
+	case r == 0x00A9, r == 0x00AE, r == 0x203C, r == 0x2049, r == 0x2122, r == 0x2139:
+		return true
+	case 0x2194 <= r && r <= 0x21FF, 0x2300 <= r && r <= 0x23FF, 0x25A0 <= r && r <= 0x27BF:
+		return true
+	case 0x2900 <= r && r <= 0x297F, 0x2B00 <= r && r <= 0x2BFF, r == 0x3030, r == 0x303D, r == 0x3297, r == 0x3299:
+		return true
+	case 0x1F000 <= r && r <= 0x1FAFF && !(0x1F1E6 <= r && r <= 0x1F1FF) && !(0x1F3FB <= r && r <= 0x1F3FF):
+		return true
+	}
+	return 0x1FC00 <= r && r <= 0x1FFFD
+}
+
+// nextGrapheme returns the length in bytes of the first grapheme cluster of s.
+func nextGrapheme(s string) int {
+	first, size := utf8.DecodeRuneInString(s)
+	if size == 0 {
+		return 0
+	}
+	previous := propertyOf(first)
+	// For the emoji ZWJ sequences: whether the cluster so far is a pictograph followed by extending characters.
+	inPictographic := previous == pictographicProperty
+	regionalIndicators := 0
+	if previous == regionalIndicatorProperty {
+		regionalIndicators = 1
+	}
+
+	end := size
+	for end < len(s) {
+		r, size := utf8.DecodeRuneInString(s[end:])
+		current := propertyOf(r)
+		if graphemeBreak(previous, current, inPictographic, regionalIndicators) {
+			break
+		}
+
+		switch {
+		case current == pictographicProperty:
+			inPictographic = true
+		case current != extendProperty && current != zwjProperty:
+			inPictographic = false
+		}
+		if current == regionalIndicatorProperty {
+			regionalIndicators++
+		}
+		previous = current
+		end += size
+	}
+	return end
+}
+
+// graphemeBreak reports whether there is a cluster boundary between two characters, the rule numbers are those of
+// UAX #29.
+func graphemeBreak(previous, current graphemeProperty, inPictographic bool, regionalIndicators int) bool {
+	switch {
+	case previous == crProperty && current == lfProperty: // GB3
+		return false
+	case previous == crProperty || previous == lfProperty || previous == controlProperty: // GB4
+		return true
+	case current == crProperty || current == lfProperty || current == controlProperty: // GB5
+		return true
+	case previous == hangulLProperty && (current == hangulLProperty || current == hangulVProperty ||
+		current == hangulLVProperty || current == hangulLVTProperty): // GB6
+		return false
+	case (previous == hangulLVProperty || previous == hangulVProperty) &&
+		(current == hangulVProperty || current == hangulTProperty): // GB7
+		return false
+	case (previous == hangulLVTProperty || previous == hangulTProperty) && current == hangulTProperty: // GB8
+		return false
+	case current == extendProperty || current == zwjProperty || current == spacingMarkProperty: // GB9, GB9a
+		return false
+	case previous == zwjProperty && current == pictographicProperty && inPictographic: // GB11
+		return false
+	case previous == regionalIndicatorProperty && current == regionalIndicatorProperty: // GB12, GB13
+		// Regional indicators pair up into flags, a third one starts a new flag.
+		return regionalIndicators%2 == 0
+	}
+	return true // GB999
+}
+
+// Graphemes splits s into its grapheme clusters.
+func Graphemes(s string) []string {
+	var clusters []string
+	for s != "" {
+		size := nextGrapheme(s)
+		clusters = append(clusters, s[:size])
+		s = s[size:]
+	}
+	return clusters
+}
+
+// GraphemeCount returns the number of user perceived characters in s, len counts bytes and
+// utf8.RuneCountInString counts runes.
+func GraphemeCount(s string) int {
+	count := 0
+	for s != "" {
+		s = s[nextGrapheme(s):]
+		count++
+	}
+	return count
+}
@@ -0,0 +1,47 @@
+package strutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGraphemes(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []string
+	}{
+		{"empty", "", nil},
+		{"ascii", "abc", []string{"a", "b", "c"}},
+		{"precomposed accent", "noël", []string{"n", "o", "ë", "l"}},
+		{"combining accent", "noël", []string{"n", "o", "ë", "l"}},
+		{"several combining marks", "ạ́b", []string{"ạ́", "b"}},
+		{"leading combining mark", "́a", []string{"́", "a"}},
+		{"crlf", "a\r\nb", []string{"a", "\r\n", "b"}},
+		{"lfcr", "\n\r", []string{"\n", "\r"}},
+		{"control", "a\tb", []string{"a", "\t", "b"}},
+		{"zwj family", "👨‍👩‍👧!", []string{"👨‍👩‍👧", "!"}},
+		{"zwj profession", "👩🏽‍💻", []string{"👩🏽‍💻"}},
+		{"zwj without a pictograph before it", "a‍👍", []string{"a‍", "👍"}},
+		{"skin tone", "👍🏽👍", []string{"👍🏽", "👍"}},
+		{"variation selector", "❤️", []string{"❤️"}},
+		{"flag", "🇮🇳", []string{"🇮🇳"}},
+		{"flags pair up", "🇮🇳🇩🇪", []string{"🇮🇳", "🇩🇪"}},
+		{"odd regional indicator", "🇮🇳🇩", []string{"🇮🇳", "🇩"}},
+		{"hangul jamo", "각", []string{"각"}},
+		{"hangul syllables", "한국", []string{"한", "국"}},
+		{"hangul lv and trailing consonant", "각", []string{"각"}},
+		{"spacing mark", "कि", []string{"कि"}},
+		{"wide", "日本語", []string{"日", "本", "語"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Graphemes(test.s); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Graphemes(%q) = %q, want %q", test.s, got, test.want)
+			}
+			if got := GraphemeCount(test.s); got != len(test.want) {
+				t.Errorf("GraphemeCount(%q) = %d, want %d", test.s, got, len(test.want))
+			}
+		})
+	}
+}
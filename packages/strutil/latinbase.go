@@ -0,0 +1,101 @@
+// Code generated by latinbase_gen.go in sorting/sortutil; DO NOT EDIT.
+
+package strutil
+
+// latinBase maps the lower case accented letters of Latin-1 Supplement and Latin Extended-A to their base letters.
+var latinBase = map[rune]string{
+	'ß': "ss",
+	'à': "a",
+	'á': "a",
+	'â': "a",
+	'ã': "a",
+	'ä': "a",
+	'å': "a",
+	'æ': "ae",
+	'ç': "c",
+	'è': "e",
+	'é': "e",
+	'ê': "e",
+	'ë': "e",
+	'ì': "i",
+	'í': "i",
+	'î': "i",
+	'ï': "i",
+	'ð': "d",
+	'ñ': "n",
+	'ò': "o",
+	'ó': "o",
+	'ô': "o",
+	'õ': "o",
+	'ö': "o",
+	'ø': "o",
+	'ù': "u",
+	'ú': "u",
+	'û': "u",
+	'ü': "u",
+	'ý': "y",
+	'þ': "th",
+	'ÿ': "y",
+	'ā': "a",
+	'ă': "a",
+	'ą': "a",
+	'ć': "c",
+	'ĉ': "c",
+	'ċ': "c",
+	'č': "c",
+	'ď': "d",
+	'đ': "d",
+	'ē': "e",
+	'ĕ': "e",
+	'ė': "e",
+	'ę': "e",
+	'ě': "e",
+	'ĝ': "g",
+	'ğ': "g",
+	'ġ': "g",
+	'ģ': "g",
+	'ĥ': "h",
+	'ħ': "h",
+	'ĩ': "i",
+	'ī': "i",
+	'ĭ': "i",
+	'į': "i",
+	'ı': "i",
+	'ĳ': "ij",
+	'ĵ': "j",
+	'ķ': "k",
+	'ĺ': "l",
+	'ļ': "l",
+	'ľ': "l",
+	'ŀ': "l",
+	'ł': "l",
+	'ń': "n",
+	'ņ': "n",
+	'ň': "n",
+	'ŋ': "n",
+	'ō': "o",
+	'ŏ': "o",
+	'ő': "o",
+	'œ': "oe",
+	'ŕ': "r",
+	'ŗ': "r",
+	'ř': "r",
+	'ś': "s",
+	'ŝ': "s",
+	'ş': "s",
+	'š': "s",
+	'ţ': "t",
+	'ť': "t",
+	'ŧ': "t",
+	'ũ': "u",
+	'ū': "u",
+	'ŭ': "u",
+	'ů': "u",
+	'ű': "u",
+	'ų': "u",
+	'ŵ': "w",
+	'ŷ': "y",
+	'ź': "z",
+	'ż': "z",
+	'ž': "z",
+}
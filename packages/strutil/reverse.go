@@ -1,6 +1,22 @@
 package strutil
 
+import "strings"
+
+// Reverse reverses the grapheme clusters of s, so combining accents stay on their letters and emoji sequences
+// and flags stay whole: Reverse("noël 🇮🇳") is "🇮🇳 lëon".
 func Reverse(s string) string {
+	clusters := Graphemes(s)
+	var reversed strings.Builder
+	reversed.Grow(len(s))
+	for i := len(clusters) - 1; i >= 0; i-- {
+		reversed.WriteString(clusters[i])
+	}
+	return reversed.String()
+}
+
+// ReverseRunes reverses the runes of s. It is only correct for text without combining characters or emoji
+// sequences, e.g. "noël" comes out as "l̈eon", with the diaeresis moved onto the "l".
+func ReverseRunes(s string) string {
 	runes := []rune(s)
 	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
 		runes[i], runes[j] = runes[j], runes[i]
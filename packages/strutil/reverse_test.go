@@ -0,0 +1,27 @@
+package strutil
+
+import "testing"
+
+func TestReverse(t *testing.T) {
+	tests := []struct {
+		s        string
+		want     string
+		runeWise string
+	}{
+		{"", "", ""},
+		{"abc", "cba", "cba"},
+		{"noël 🇮🇳", "🇮🇳 lëon", "🇳🇮 lëon"},
+		// The diaeresis must stay on its "e", reversing the runes moves it onto the "l".
+		{"noël", "lëon", "l̈eon"},
+		{"ab👨‍👩‍👧", "👨‍👩‍👧ba", "👧‍👩‍👨ba"},
+		{"👍🏽!", "!👍🏽", "!🏽👍"},
+	}
+	for _, test := range tests {
+		if got := Reverse(test.s); got != test.want {
+			t.Errorf("Reverse(%q) = %q, want %q", test.s, got, test.want)
+		}
+		if got := ReverseRunes(test.s); got != test.runeWise {
+			t.Errorf("ReverseRunes(%q) = %q, want %q", test.s, got, test.runeWise)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package strutil
+
+// latinBase is generated from the table in sorting/sortutil, the two modules can not import each other.
+//go:generate go run ../../sorting/sortutil/latinbase_gen.go -package strutil -o latinbase.go
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify turns s into a string fit for a URL path: lower case, accents removed from Latin letters, apostrophes
+// dropped and every other run of characters which are not letters or digits replaced by a single "-", e.g.
+// "Crème Brûlée: l'été à Paris!" becomes "creme-brulee-lete-a-paris". Letters of other scripts are kept, lower
+// cased, as browsers display them in URLs.
+func Slugify(s string) string {
+	var slug strings.Builder
+	separator := false
+	for _, r := range s {
+		r = unicode.ToLower(r)
+		switch {
+		case r == '\'' || r == '’' || unicode.IsMark(r):
+			// Combining accents are dropped with the apostrophes, "é" becomes "e" like "é" does.
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if separator && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			separator = false
+			if base, ok := latinBase[r]; ok {
+				slug.WriteString(base)
+			} else {
+				slug.WriteRune(r)
+			}
+		default:
+			separator = true
+		}
+	}
+	return slug.String()
+}
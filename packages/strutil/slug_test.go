@@ -0,0 +1,27 @@
+package strutil
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		s    string
+		want string
+	}{
+		{"", ""},
+		{"Hello, World!", "hello-world"},
+		{"Crème Brûlée: l'été à Paris!", "creme-brulee-lete-a-paris"},
+		{"Don’t stop", "dont-stop"},
+		{"café", "cafe"},
+		{"Straße Œuvre Æsir", "strasse-oeuvre-aesir"},
+		{"  --leading and trailing--  ", "leading-and-trailing"},
+		{"Go 1.20 release", "go-1-20-release"},
+		{"Привет мир", "привет-мир"},
+		{"日本語 テキスト", "日本語-テキスト"},
+		{"👍 emoji 👍", "emoji"},
+	}
+	for _, test := range tests {
+		if got := Slugify(test.s); got != test.want {
+			t.Errorf("Slugify(%q) = %q, want %q", test.s, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package strutil
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Width returns the number of columns s takes in a monospaced terminal: East Asian wide characters and emoji take
+// two columns, combining marks and control characters none and everything else one. The East Asian Width property
+// is approximated by the blocks of wide characters.
+func Width(s string) int {
+	width := 0
+	for s != "" {
+		size := nextGrapheme(s)
+		width += graphemeWidth(s[:size])
+		s = s[size:]
+	}
+	return width
+}
+
+func graphemeWidth(cluster string) int {
+	first, _ := utf8.DecodeRuneInString(cluster)
+	switch {
+	case unicode.In(first, unicode.Cc, unicode.Cf, unicode.Mn, unicode.Me, unicode.Zl, unicode.Zp):
+		return 0
+	case isWide(first), first >= 0x1F000 && isPictographic(first), propertyOf(first) == regionalIndicatorProperty:
+		return 2
+	case strings.ContainsRune(cluster, 0xFE0F):
+		// Variation selector 16 asks for the emoji presentation of characters like "❤".
+		return 2
+	}
+	return 1
+}
+
+func isWide(r rune) bool {
+	switch {
+	case 0x1100 <= r && r <= 0x115F, 0x2E80 <= r && r <= 0x303E, 0x3041 <= r && r <= 0x33FF:
+		return true
+	case 0x3400 <= r && r <= 0x4DBF, 0x4E00 <= r && r <= 0x9FFF, 0xA000 <= r && r <= 0xA4CF:
+		return true
+	case 0xAC00 <= r && r <= 0xD7A3, 0xF900 <= r && r <= 0xFAFF, 0xFE30 <= r && r <= 0xFE4F:
+		return true
+	case 0xFF00 <= r && r <= 0xFF60, 0xFFE0 <= r && r <= 0xFFE6:
+		return true
+	}
+	return 0x20000 <= r && r <= 0x3FFFD
+}
+
+// Truncate shortens s to at most width columns, ending it with tail (e.g. "…") when anything was cut off. It never
+// cuts a grapheme cluster or a wide character in half, so the result may be narrower than width.
+func Truncate(s string, width int, tail string) string {
+	if Width(s) <= width {
+		return s
+	}
+	room := width - Width(tail)
+	if room < 0 {
+		// The tail itself does not fit.
+		room, tail = width, ""
+	}
+
+	used, end := 0, 0
+	for end < len(s) {
+		size := nextGrapheme(s[end:])
+		clusterWidth := graphemeWidth(s[end : end+size])
+		if used+clusterWidth > room {
+			break
+		}
+		used += clusterWidth
+		end += size
+	}
+	return s[:end] + tail
+}
+
+// PadLeft right aligns s in width columns by adding pad in front of it. When pad is wide and does not fill the
+// columns exactly, the rest is filled with spaces. s is returned as is when it is already wide enough.
+func PadLeft(s string, width int, pad rune) string {
+	return padding(width-Width(s), pad) + s
+}
+
+// PadRight left aligns s in width columns by adding pad after it.
+func PadRight(s string, width int, pad rune) string {
+	return s + padding(width-Width(s), pad)
+}
+
+// PadCenter centers s in width columns, an odd column of padding goes to the right.
+func PadCenter(s string, width int, pad rune) string {
+	missing := width - Width(s)
+	if missing <= 0 {
+		return s
+	}
+	return padding(missing/2, pad) + s + padding(missing-missing/2, pad)
+}
+
+func padding(columns int, pad rune) string {
+	if columns <= 0 {
+		return ""
+	}
+	padWidth := Width(string(pad))
+	if padWidth <= 0 {
+		pad, padWidth = ' ', 1
+	}
+	count := columns / padWidth
+	return strings.Repeat(string(pad), count) + strings.Repeat(" ", columns-count*padWidth)
+}
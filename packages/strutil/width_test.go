@@ -0,0 +1,96 @@
+package strutil
+
+import "testing"
+
+func TestWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"noël", 4},
+		{"́", 0},
+		{"a\tb", 2},
+		{"日本語", 6},
+		{"한국", 4},
+		{"ｆｕｌｌ", 8},
+		{"👍", 2},
+		{"👍🏽", 2},
+		{"👨‍👩‍👧", 2},
+		{"🇮🇳", 2},
+		{"❤", 1},
+		{"❤️", 2},
+		{"a👍b", 4},
+	}
+	for _, test := range tests {
+		if got := Width(test.s); got != test.want {
+			t.Errorf("Width(%q) = %d, want %d", test.s, got, test.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width int
+		tail  string
+		want  string
+	}{
+		{"fits", "hello", 5, "…", "hello"},
+		{"cut with tail", "hello world", 8, "…", "hello w…"},
+		{"cut without tail", "hello world", 5, "", "hello"},
+		{"longer tail", "hello world", 8, "...", "hello..."},
+		{"tail does not fit", "hello", 2, "...", "he"},
+		{"zero width", "hello", 0, "…", ""},
+		{"keeps combining marks", "noël!", 4, "…", "noë…"},
+		{"never splits a wide character", "日本語", 4, "…", "日…"},
+		{"narrower than width", "日本語", 5, "", "日本"},
+		{"keeps zwj sequences whole", "👨‍👩‍👧👨‍👩‍👧", 3, "…", "👨‍👩‍👧…"},
+		{"keeps flags whole", "🇮🇳🇩🇪🇫🇷", 4, "", "🇮🇳🇩🇪"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Truncate(test.s, test.width, test.tail)
+			if got != test.want {
+				t.Errorf("Truncate(%q, %d, %q) = %q, want %q", test.s, test.width, test.tail, got, test.want)
+			}
+			if Width(got) > test.width {
+				t.Errorf("Truncate(%q, %d, %q) is %d columns wide", test.s, test.width, test.tail, Width(got))
+			}
+		})
+	}
+}
+
+func TestPad(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		width  int
+		pad    rune
+		left   string
+		right  string
+		center string
+	}{
+		{"ascii", "ab", 5, '.', "...ab", "ab...", ".ab.."},
+		{"already wide enough", "abc", 2, '.', "abc", "abc", "abc"},
+		{"wide text", "日本", 6, '-', "--日本", "日本--", "-日本-"},
+		{"wide pad", "a", 4, '日', "日 a", "a日 ", " a日"},
+		{"zero width pad falls back to spaces", "a", 3, '́', "  a", "a  ", " a "},
+		{"emoji", "👍🏽", 4, '*', "**👍🏽", "👍🏽**", "*👍🏽*"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := PadLeft(test.s, test.width, test.pad); got != test.left {
+				t.Errorf("PadLeft(%q, %d, %q) = %q, want %q", test.s, test.width, test.pad, got, test.left)
+			}
+			if got := PadRight(test.s, test.width, test.pad); got != test.right {
+				t.Errorf("PadRight(%q, %d, %q) = %q, want %q", test.s, test.width, test.pad, got, test.right)
+			}
+			if got := PadCenter(test.s, test.width, test.pad); got != test.center {
+				t.Errorf("PadCenter(%q, %d, %q) = %q, want %q", test.s, test.width, test.pad, got, test.center)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package strutil
+
+import "strings"
+
+// Wrap breaks the lines of s so that none is wider than width columns, breaking at white space. Words wider than
+// width are broken between grapheme clusters. Existing line breaks are kept, and the white space between words of
+// a line is collapsed to a single space.
+func Wrap(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = wrapLine(line, width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func wrapLine(line string, width int) string {
+	var wrapped strings.Builder
+	lineWidth := 0
+	for _, word := range strings.Fields(line) {
+		wordWidth := Width(word)
+		switch {
+		case lineWidth == 0:
+		case lineWidth+1+wordWidth <= width:
+			wrapped.WriteByte(' ')
+			lineWidth++
+		default:
+			wrapped.WriteByte('\n')
+			lineWidth = 0
+		}
+
+		for wordWidth > width-lineWidth {
+			// The word does not fit on a line of its own, fill the line with as much of it as fits.
+			head := Truncate(word, width-lineWidth, "")
+			if head == "" && lineWidth == 0 {
+				// A single cluster wider than the line, e.g. an emoji on a 1 column line, goes on a line by itself.
+				head = word[:nextGrapheme(word)]
+			}
+			wrapped.WriteString(head)
+			word = word[len(head):]
+			if word == "" {
+				wordWidth = Width(head)
+				break
+			}
+			wrapped.WriteByte('\n')
+			wordWidth = Width(word)
+			lineWidth = 0
+		}
+		wrapped.WriteString(word)
+		lineWidth += wordWidth
+	}
+	return wrapped.String()
+}
@@ -0,0 +1,31 @@
+package strutil
+
+import "testing"
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width int
+		want  string
+	}{
+		{"fits", "hello world", 11, "hello world"},
+		{"breaks at spaces", "the quick brown fox", 10, "the quick\nbrown fox"},
+		{"collapses white space", "a   b\tc", 10, "a b c"},
+		{"keeps line breaks", "ab cd\nef", 2, "ab\ncd\nef"},
+		{"breaks long words", "abcdefgh", 3, "abc\ndef\ngh"},
+		{"long word starts a new line", "ab cdefgh", 4, "ab\ncdef\ngh"},
+		{"counts wide characters", "日本語 日本語", 6, "日本語\n日本語"},
+		{"breaks between wide characters", "日本語", 4, "日本\n語"},
+		{"keeps zwj sequences whole", "👨‍👩‍👧👨‍👩‍👧", 3, "👨‍👩‍👧\n👨‍👩‍👧"},
+		{"cluster wider than the line", "👍a", 1, "👍\na"},
+		{"non-positive width", "a b", 0, "a b"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Wrap(test.s, test.width); got != test.want {
+				t.Errorf("Wrap(%q, %d) = %q, want %q", test.s, test.width, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+var secret = []byte("shared secret")
+
+// handshake runs both sides of the handshake over an in-memory connection and returns their errors.
+func handshake(serverSecret, clientSecret []byte) (serverErr, clientErr error) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	done := make(chan error)
+	go func() {
+		done <- ServerHandshake(server, serverSecret, time.Second)
+	}()
+	clientErr = ClientHandshake(client, clientSecret, time.Second)
+	return <-done, clientErr
+}
+
+func TestHandshake(t *testing.T) {
+	tests := []struct {
+		name         string
+		serverSecret []byte
+		clientSecret []byte
+		want         error
+	}{
+		{"same secret", secret, secret, nil},
+		{"wrong secret", secret, []byte("wrong secret"), ErrHandshakeRejected},
+		{"missing secret", secret, nil, ErrHandshakeRejected},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			serverErr, clientErr := handshake(test.serverSecret, test.clientSecret)
+			if !errors.Is(serverErr, test.want) {
+				t.Errorf("ServerHandshake = %v, want %v", serverErr, test.want)
+			}
+			if !errors.Is(clientErr, test.want) {
+				t.Errorf("ClientHandshake = %v, want %v", clientErr, test.want)
+			}
+		})
+	}
+}
+
+func TestTruncatedHandshake(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	done := make(chan error)
+	go func() {
+		done <- ServerHandshake(server, secret, time.Second)
+	}()
+
+	nonce := make([]byte, nonceSize)
+	if _, err := io.ReadFull(client, nonce); err != nil {
+		t.Fatal(err)
+	}
+	// Half of the answer, then the client goes away.
+	client.Write(sign(secret, nonce)[:10])
+	client.Close()
+	if err := <-done; !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("ServerHandshake = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestHandshakeTimeout(t *testing.T) {
+	t.Run("client never answers", func(t *testing.T) {
+		server, client := net.Pipe()
+		defer server.Close()
+		defer client.Close()
+		// The nonce is read so the server gets to wait for the answer.
+		go io.Copy(io.Discard, client)
+
+		start := time.Now()
+		err := ServerHandshake(server, secret, 50*time.Millisecond)
+		if !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("ServerHandshake = %v, want a deadline error", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("ServerHandshake returned after %s, want about the 50ms timeout", elapsed)
+		}
+	})
+	t.Run("server never sends a nonce", func(t *testing.T) {
+		server, client := net.Pipe()
+		defer server.Close()
+		defer client.Close()
+		if err := ClientHandshake(client, secret, 50*time.Millisecond); !errors.Is(err, os.ErrDeadlineExceeded) {
+			t.Errorf("ClientHandshake = %v, want a deadline error", err)
+		}
+	})
+}
+
+func TestHandshakeClearsTheDeadline(t *testing.T) {
+	server, client := net.Pipe()
+	defer server.Close()
+	defer client.Close()
+	done := make(chan error)
+	go func() {
+		done <- ServerHandshake(server, secret, 50*time.Millisecond)
+	}()
+	if err := ClientHandshake(client, secret, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// net/rpc takes over the connection next, it must not inherit the handshake's deadline.
+	time.Sleep(100 * time.Millisecond)
+	go client.Write([]byte{42})
+	buf := make([]byte, 1)
+	if _, err := server.Read(buf); err != nil {
+		t.Errorf("reading after the handshake failed: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// certificate is a certificate and its key, written to PEM files in the test's temporary directory.
+type certificate struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// newCertificate creates a certificate for name, signed by parent or self signed when parent is nil.
+func newCertificate(t *testing.T, name string, parent *certificate, usage x509.ExtKeyUsage) *certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	result := &certificate{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".pem"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+	writePEM(t, result.certFile, "CERTIFICATE", der)
+	writePEM(t, result.keyFile, "EC PRIVATE KEY", keyDER)
+	return result
+}
+
+func writePEM(t *testing.T, file, kind string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serveTLS accepts a single connection on a TLS listener and returns the result of its handshake.
+func serveTLS(t *testing.T, config *tls.Config) (string, <-chan error) {
+	t.Helper()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	handshakes := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			handshakes <- err
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+		err = conn.(*tls.Conn).Handshake()
+		if err == nil {
+			// Echo a byte, so the client knows the server accepted it.
+			buf := make([]byte, 1)
+			if _, err = conn.Read(buf); err == nil {
+				_, err = conn.Write(buf)
+			}
+		}
+		handshakes <- err
+	}()
+	return listener.Addr().String(), handshakes
+}
+
+// roundTrip dials the server and exchanges a single byte with it.
+func roundTrip(address string, config *tls.Config) error {
+	conn, err := Dial(address, 5*time.Second, config, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte{42}); err != nil {
+		return err
+	}
+	_, err = conn.Read(make([]byte, 1))
+	return err
+}
+
+func TestTLS(t *testing.T) {
+	ca := newCertificate(t, "ca", nil, x509.ExtKeyUsageAny)
+	server := newCertificate(t, "localhost", ca, x509.ExtKeyUsageServerAuth)
+	client := newCertificate(t, "client", ca, x509.ExtKeyUsageClientAuth)
+	otherCA := newCertificate(t, "other-ca", nil, x509.ExtKeyUsageAny)
+	stranger := newCertificate(t, "stranger", otherCA, x509.ExtKeyUsageClientAuth)
+
+	tests := []struct {
+		name       string
+		mutual     bool
+		clientCA   string
+		clientCert *certificate
+		wantErr    bool
+	}{
+		{name: "server verified", clientCA: ca.certFile},
+		{name: "unknown server CA", clientCA: otherCA.certFile, wantErr: true},
+		{name: "mutual", mutual: true, clientCA: ca.certFile, clientCert: client},
+		{name: "mutual without client certificate", mutual: true, clientCA: ca.certFile, wantErr: true},
+		{name: "mutual with a certificate of another CA", mutual: true, clientCA: ca.certFile, clientCert: stranger, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			clientCAFile := ""
+			if test.mutual {
+				clientCAFile = ca.certFile
+			}
+			serverConfig, err := ServerTLSConfig(server.certFile, server.keyFile, clientCAFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var certFile, keyFile string
+			if test.clientCert != nil {
+				certFile, keyFile = test.clientCert.certFile, test.clientCert.keyFile
+			}
+			clientConfig, err := ClientTLSConfig(test.clientCA, certFile, keyFile, "localhost")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			address, handshakes := serveTLS(t, serverConfig)
+			clientErr := roundTrip(address, clientConfig)
+			serverErr := <-handshakes
+			if test.wantErr && (clientErr == nil || serverErr == nil) {
+				t.Errorf("the connection was accepted: client error %v, server error %v", clientErr, serverErr)
+			}
+			if !test.wantErr && (clientErr != nil || serverErr != nil) {
+				t.Errorf("the connection was rejected: client error %v, server error %v", clientErr, serverErr)
+			}
+		})
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ca := newCertificate(t, "ca", nil, x509.ExtKeyUsageAny)
+
+	if _, err := ServerTLSConfig(missing, missing, ""); err == nil {
+		t.Error("ServerTLSConfig accepted a missing certificate")
+	}
+	if _, err := ServerTLSConfig(ca.certFile, ca.keyFile, empty); err == nil {
+		t.Error("ServerTLSConfig accepted a client CA file without certificates")
+	}
+	if _, err := ClientTLSConfig(missing, "", "", "localhost"); err == nil {
+		t.Error("ClientTLSConfig accepted a missing CA file")
+	}
+	if _, err := ClientTLSConfig("", ca.certFile, "", "localhost"); err == nil {
+		t.Error("ClientTLSConfig accepted a client certificate without its key")
+	}
+}
@@ -0,0 +1,447 @@
+package balancer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/rpc"
+	shared_types "rpc/shared_types"
+	"sync"
+	"time"
+)
+
+// Strategy decides which backend receives the next call.
+type Strategy int
+
+const (
+	// RoundRobin cycles through the healthy backends one after another.
+	RoundRobin Strategy = iota
+	// LeastOutstanding picks the healthy backend with the fewest in-flight calls.
+	LeastOutstanding
+)
+
+var ErrNoHealthyBackends = errors.New("balancer: no healthy backends available")
+var ErrClosed = errors.New("balancer: closed")
+
+// ErrTimeout is returned by calls which did not complete within the configured CallTimeout.
+var ErrTimeout = errors.New("balancer: call timed out")
+
+// errNotServing is returned by health checks which reached the backend, but were answered with a status other than
+// serving. The connection is fine in that case, so there is no point in dialing again.
+var errNotServing = errors.New("balancer: backend is not serving")
+
+type Config struct {
+	Addresses []string
+	Strategy  Strategy
+	// Number of connections kept open to every backend.
+	PoolSize int
+	// How often unhealthy (and healthy) backends are probed.
+	HealthCheckInterval time.Duration
+	// Number of consecutive failed probes or calls after which a backend is ejected.
+	MaxFailures int
+	DialTimeout time.Duration
+	// How long a call may take before it fails with ErrTimeout, so a hung backend can not block its callers forever.
+	CallTimeout time.Duration
+	// Dial is used to open connections to a backend, it defaults to a plain tcp dial.
+	Dial func(address string, timeout time.Duration) (*rpc.Client, error)
+}
+
+type backend struct {
+	address string
+	// A nil entry is a broken connection which is being dialed again.
+	clients     []*rpc.Client
+	nextClient  int
+	outstanding int
+	failures    int
+	healthy     bool
+}
+
+// Balancer spreads the calls across a pool of rpc clients connected to multiple word count servers.
+// A backend is ejected once it fails MaxFailures consecutive calls or health probes, and is brought
+// back as soon as a health probe succeeds again. A pooled connection which turns out to be closed is replaced on its
+// own, without affecting the other connections to the same backend.
+type Balancer struct {
+	config   Config
+	mu       sync.Mutex
+	backends []*backend
+	next     int
+	closed   bool
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+func defaultDial(address string, timeout time.Duration) (*rpc.Client, error) {
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return rpc.NewClient(conn), nil
+}
+
+func New(config Config) (*Balancer, error) {
+	if len(config.Addresses) == 0 {
+		return nil, errors.New("balancer: at least one address is required")
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = 2
+	}
+	if config.HealthCheckInterval <= 0 {
+		config.HealthCheckInterval = 5 * time.Second
+	}
+	if config.MaxFailures <= 0 {
+		config.MaxFailures = 3
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 2 * time.Second
+	}
+	if config.CallTimeout <= 0 {
+		config.CallTimeout = 10 * time.Second
+	}
+	if config.Dial == nil {
+		config.Dial = defaultDial
+	}
+
+	balancer := &Balancer{config: config, stop: make(chan struct{})}
+	for _, address := range config.Addresses {
+		backend := &backend{address: address}
+		// A backend which can not be dialed or is not serving right away is not fatal, it starts out ejected and
+		// the health checker keeps probing it.
+		if err := balancer.connect(backend); err != nil {
+			log.Printf("balancer: unable to connect to %s: %s", address, err.Error())
+		} else if err := balancer.checkHealth(backend); err != nil {
+			log.Printf("balancer: %s", err.Error())
+		} else {
+			backend.healthy = true
+		}
+		balancer.backends = append(balancer.backends, backend)
+	}
+
+	balancer.wg.Add(1)
+	go balancer.healthCheckLoop()
+	return balancer, nil
+}
+
+// connect replaces the backend's pool with fresh connections, it must not be called with the balancer lock held.
+// It leaves the health of the backend alone: accepting connections does not mean a backend is able to serve.
+func (balancer *Balancer) connect(backend *backend) error {
+	clients := make([]*rpc.Client, 0, balancer.config.PoolSize)
+	for i := 0; i < balancer.config.PoolSize; i++ {
+		client, err := balancer.config.Dial(backend.address, balancer.config.DialTimeout)
+		if err != nil {
+			for _, client := range clients {
+				client.Close()
+			}
+			return err
+		}
+		clients = append(clients, client)
+	}
+
+	balancer.mu.Lock()
+	defer balancer.mu.Unlock()
+	if balancer.closed {
+		for _, client := range clients {
+			client.Close()
+		}
+		return ErrClosed
+	}
+	old := backend.clients
+	backend.clients = clients
+	backend.nextClient = 0
+	for _, client := range old {
+		if client != nil {
+			client.Close()
+		}
+	}
+	return nil
+}
+
+// available reports whether calls can be sent to the backend, it must be called with the balancer lock held.
+func (backend *backend) available() bool {
+	if !backend.healthy {
+		return false
+	}
+	for _, client := range backend.clients {
+		if client != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// pick selects a backend and a client from its pool according to the configured strategy, skipping the connections
+// which are being dialed again.
+func (balancer *Balancer) pick() (*backend, int, *rpc.Client, error) {
+	balancer.mu.Lock()
+	defer balancer.mu.Unlock()
+	if balancer.closed {
+		return nil, 0, nil, ErrClosed
+	}
+
+	var chosen *backend
+	switch balancer.config.Strategy {
+	case LeastOutstanding:
+		for _, backend := range balancer.backends {
+			if !backend.available() {
+				continue
+			}
+			if chosen == nil || backend.outstanding < chosen.outstanding {
+				chosen = backend
+			}
+		}
+	default:
+		for i := 0; i < len(balancer.backends); i++ {
+			backend := balancer.backends[(balancer.next+i)%len(balancer.backends)]
+			if backend.available() {
+				chosen = backend
+				balancer.next = (balancer.next + i + 1) % len(balancer.backends)
+				break
+			}
+		}
+	}
+	if chosen == nil {
+		return nil, 0, nil, ErrNoHealthyBackends
+	}
+
+	for {
+		slot := chosen.nextClient
+		chosen.nextClient = (chosen.nextClient + 1) % len(chosen.clients)
+		if client := chosen.clients[slot]; client != nil {
+			chosen.outstanding++
+			return chosen, slot, client, nil
+		}
+	}
+}
+
+// Call invokes serviceMethod on one of the healthy backends. Errors returned by the remote method
+// itself (rpc.ServerError) do not count against the backend's health. A call failing because its connection was
+// closed has the connection replaced, so the following calls do not keep failing on it.
+func (balancer *Balancer) Call(serviceMethod string, args any, reply any) error {
+	backend, slot, client, err := balancer.pick()
+	if err != nil {
+		return err
+	}
+
+	err = call(client, serviceMethod, args, reply, balancer.config.CallTimeout)
+
+	balancer.mu.Lock()
+	defer balancer.mu.Unlock()
+	backend.outstanding--
+	var serverError rpc.ServerError
+	if err != nil && !errors.As(err, &serverError) {
+		balancer.recordFailure(backend)
+	} else {
+		backend.failures = 0
+	}
+	if broken(err) {
+		balancer.replace(backend, slot, client)
+	}
+	return err
+}
+
+// call works like client.Call, but gives up after timeout. The call keeps running in the background then, its
+// reply is dropped once it arrives.
+func call(client *rpc.Client, serviceMethod string, args any, reply any, timeout time.Duration) error {
+	pending := client.Go(serviceMethod, args, reply, make(chan *rpc.Call, 1))
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-pending.Done:
+		return pending.Error
+	case <-timer.C:
+		return fmt.Errorf("%w: %s after %s", ErrTimeout, serviceMethod, timeout)
+	}
+}
+
+// broken reports whether err means the connection the call was sent over is closed: net/rpc fails the calls in
+// flight with io.ErrUnexpectedEOF (or io.EOF) when the connection breaks, and every later call with ErrShutdown.
+func broken(err error) bool {
+	return errors.Is(err, rpc.ErrShutdown) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// replace takes a broken connection out of the backend's pool and dials a new one in the background, it must be
+// called with the balancer lock held.
+func (balancer *Balancer) replace(backend *backend, slot int, client *rpc.Client) {
+	// The connection may have been replaced already, by another call or by the health checker.
+	if balancer.closed || slot >= len(backend.clients) || backend.clients[slot] != client {
+		return
+	}
+	backend.clients[slot] = nil
+	client.Close()
+	balancer.wg.Add(1)
+	go func() {
+		defer balancer.wg.Done()
+		if _, err := balancer.redial(backend, slot, nil); err != nil {
+			log.Printf("balancer: unable to reconnect to %s: %s", backend.address, err.Error())
+		}
+	}()
+}
+
+// redial dials a new connection for the slot of the backend's pool which still holds old, and returns the
+// connection the slot holds afterwards. It must not be called with the balancer lock held.
+func (balancer *Balancer) redial(backend *backend, slot int, old *rpc.Client) (*rpc.Client, error) {
+	client, err := balancer.config.Dial(backend.address, balancer.config.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	balancer.mu.Lock()
+	defer balancer.mu.Unlock()
+	if balancer.closed {
+		client.Close()
+		return nil, ErrClosed
+	}
+	if slot >= len(backend.clients) || backend.clients[slot] != old {
+		// The pool changed while dialing, keep what is there now.
+		client.Close()
+		if slot >= len(backend.clients) || backend.clients[slot] == nil {
+			return nil, fmt.Errorf("balancer: connection to %s was replaced while dialing", backend.address)
+		}
+		return backend.clients[slot], nil
+	}
+	backend.clients[slot] = client
+	if old != nil {
+		old.Close()
+	}
+	return client, nil
+}
+
+func (balancer *Balancer) Compute(args *shared_types.WordCountRequest, reply *shared_types.WordCountReply) error {
+	return balancer.Call("WordCountServer.Compute", args, reply)
+}
+
+// recordFailure must be called with the balancer lock held.
+func (balancer *Balancer) recordFailure(backend *backend) {
+	backend.failures++
+	if backend.healthy && backend.failures >= balancer.config.MaxFailures {
+		log.Println("balancer: ejecting unhealthy backend", backend.address)
+		backend.healthy = false
+	}
+}
+
+func (balancer *Balancer) healthCheckLoop() {
+	defer balancer.wg.Done()
+	ticker := time.NewTicker(balancer.config.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-balancer.stop:
+			return
+		case <-ticker.C:
+			for _, backend := range balancer.snapshot() {
+				balancer.probe(backend)
+			}
+		}
+	}
+}
+
+func (balancer *Balancer) snapshot() []*backend {
+	balancer.mu.Lock()
+	defer balancer.mu.Unlock()
+	return append([]*backend(nil), balancer.backends...)
+}
+
+// checkHealth sends a health check over every connection of the backend's pool. A connection which is closed, or
+// missing because dialing it again failed before, is dialed again and checked once more.
+func (balancer *Balancer) checkHealth(backend *backend) error {
+	balancer.mu.Lock()
+	clients := append([]*rpc.Client(nil), backend.clients...)
+	balancer.mu.Unlock()
+	if len(clients) == 0 {
+		return fmt.Errorf("balancer: no open connections to %s", backend.address)
+	}
+
+	for slot, client := range clients {
+		err := rpc.ErrShutdown
+		if client != nil {
+			err = balancer.healthCheck(backend.address, client)
+		}
+		if broken(err) {
+			if client, err = balancer.redial(backend, slot, client); err == nil {
+				err = balancer.healthCheck(backend.address, client)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (balancer *Balancer) healthCheck(address string, client *rpc.Client) error {
+	args := &shared_types.HealthCheckRequest{Service: "WordCountServer"}
+	reply := new(shared_types.HealthCheckReply)
+	err := call(client, "WordCountServer.HealthCheck", args, reply, balancer.config.DialTimeout)
+	if errors.Is(err, ErrTimeout) {
+		return fmt.Errorf("balancer: health check to %s timed out", address)
+	}
+	if err != nil {
+		return err
+	}
+	if reply.Status != shared_types.HealthStatusServing {
+		return fmt.Errorf("%w: %s reported status %q", errNotServing, address, reply.Status)
+	}
+	return nil
+}
+
+// probe checks a single backend. A backend without any open connections is dialed, and so is an ejected backend
+// whose probe failed for any other reason than reporting it is not serving, as its old connections are likely
+// broken. Connections which are closed are replaced one by one by checkHealth. Either way a backend is only marked
+// healthy once a health check over its (new) connections reports it as serving.
+func (balancer *Balancer) probe(backend *backend) {
+	balancer.mu.Lock()
+	hasClients := len(backend.clients) > 0
+	wasHealthy := backend.healthy
+	balancer.mu.Unlock()
+
+	var err error
+	if hasClients {
+		err = balancer.checkHealth(backend)
+	}
+	redial := !hasClients || (err != nil && !errors.Is(err, errNotServing) && !wasHealthy)
+	if redial {
+		if err = balancer.connect(backend); err == nil {
+			err = balancer.checkHealth(backend)
+		}
+	}
+
+	balancer.mu.Lock()
+	defer balancer.mu.Unlock()
+	if err != nil {
+		balancer.recordFailure(backend)
+		return
+	}
+	if !backend.healthy {
+		log.Println("balancer: backend is healthy again", backend.address)
+	}
+	backend.failures = 0
+	backend.healthy = true
+}
+
+// Close stops the health checker and closes every pooled connection.
+func (balancer *Balancer) Close() error {
+	balancer.mu.Lock()
+	if balancer.closed {
+		balancer.mu.Unlock()
+		return nil
+	}
+	balancer.closed = true
+	close(balancer.stop)
+	balancer.mu.Unlock()
+
+	balancer.wg.Wait()
+
+	balancer.mu.Lock()
+	defer balancer.mu.Unlock()
+	for _, backend := range balancer.backends {
+		for _, client := range backend.clients {
+			if client != nil {
+				client.Close()
+			}
+		}
+		backend.clients = nil
+		backend.healthy = false
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package balancer
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	shared_types "rpc/shared_types"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeServer stands in for a word count server, its health status can be changed while the test runs.
+type fakeServer struct {
+	mu     sync.Mutex
+	status string
+	conns  []net.Conn
+	calls  atomic.Int64
+	// Closed when the test ends, Hang blocks until then.
+	release chan struct{}
+}
+
+func (server *fakeServer) setStatus(status string) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.status = status
+}
+
+func (server *fakeServer) Compute(args *shared_types.WordCountRequest, reply *shared_types.WordCountReply) error {
+	server.calls.Add(1)
+	reply.Counts = map[string]int{}
+	return nil
+}
+
+func (server *fakeServer) Hang(args *shared_types.WordCountRequest, reply *shared_types.WordCountReply) error {
+	<-server.release
+	return nil
+}
+
+// kill closes the connection the server accepted as the index-th one, like a server dropping a client would.
+func (server *fakeServer) kill(index int) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	server.conns[index].Close()
+}
+
+func (server *fakeServer) accepted() int {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	return len(server.conns)
+}
+
+func (server *fakeServer) HealthCheck(args *shared_types.HealthCheckRequest, reply *shared_types.HealthCheckReply) error {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	reply.Status = server.status
+	return nil
+}
+
+func startFakeServer(t *testing.T, status string) (*fakeServer, string) {
+	t.Helper()
+	server := &fakeServer{status: status, release: make(chan struct{})}
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("WordCountServer", server); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		listener.Close()
+		close(server.release)
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mu.Lock()
+			server.conns = append(server.conns, conn)
+			server.mu.Unlock()
+			go rpcServer.ServeConn(conn)
+		}
+	}()
+	return server, listener.Addr().String()
+}
+
+func newTestBalancer(t *testing.T, addresses ...string) *Balancer {
+	t.Helper()
+	return newTestBalancerWith(t, Config{Addresses: addresses})
+}
+
+func newTestBalancerWith(t *testing.T, config Config) *Balancer {
+	t.Helper()
+	if config.HealthCheckInterval == 0 {
+		config.HealthCheckInterval = 10 * time.Millisecond
+	}
+	if config.MaxFailures == 0 {
+		config.MaxFailures = 2
+	}
+	config.DialTimeout = time.Second
+	balancer, err := New(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { balancer.Close() })
+	return balancer
+}
+
+func compute(t *testing.T, balancer *Balancer, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		err := balancer.Compute(&shared_types.WordCountRequest{Content: "a b"}, new(shared_types.WordCountReply))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// waitFor polls condition until it holds, or fails the test after a few seconds.
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func (balancer *Balancer) isHealthy(address string) bool {
+	balancer.mu.Lock()
+	defer balancer.mu.Unlock()
+	for _, backend := range balancer.backends {
+		if backend.address == address {
+			return backend.healthy
+		}
+	}
+	return false
+}
+
+func TestNotServingBackendIsNeverAdmitted(t *testing.T) {
+	serving, servingAddress := startFakeServer(t, shared_types.HealthStatusServing)
+	notServing, notServingAddress := startFakeServer(t, "NOT_SERVING")
+	balancer := newTestBalancer(t, servingAddress, notServingAddress)
+
+	// Give the health checker plenty of rounds in which it could wrongly re-admit the backend.
+	for i := 0; i < 10; i++ {
+		compute(t, balancer, 4)
+		time.Sleep(15 * time.Millisecond)
+		if balancer.isHealthy(notServingAddress) {
+			t.Fatal("a backend reporting NOT_SERVING was admitted")
+		}
+	}
+	if calls := notServing.calls.Load(); calls != 0 {
+		t.Errorf("the NOT_SERVING backend received %d calls", calls)
+	}
+	if calls := serving.calls.Load(); calls != 40 {
+		t.Errorf("the serving backend received %d calls, want 40", calls)
+	}
+}
+
+func TestEjectAndReadmit(t *testing.T) {
+	first, firstAddress := startFakeServer(t, shared_types.HealthStatusServing)
+	second, secondAddress := startFakeServer(t, shared_types.HealthStatusServing)
+	balancer := newTestBalancer(t, firstAddress, secondAddress)
+
+	compute(t, balancer, 10)
+	if first.calls.Load() != 5 || second.calls.Load() != 5 {
+		t.Fatalf("round robin sent %d and %d calls, want 5 and 5", first.calls.Load(), second.calls.Load())
+	}
+
+	first.setStatus("NOT_SERVING")
+	waitFor(t, "the backend to be ejected", func() bool { return !balancer.isHealthy(firstAddress) })
+	before := first.calls.Load()
+	compute(t, balancer, 10)
+	if calls := first.calls.Load() - before; calls != 0 {
+		t.Errorf("the ejected backend received %d calls", calls)
+	}
+
+	first.setStatus(shared_types.HealthStatusServing)
+	waitFor(t, "the backend to be re-admitted", func() bool { return balancer.isHealthy(firstAddress) })
+	before = first.calls.Load()
+	compute(t, balancer, 10)
+	if calls := first.calls.Load() - before; calls != 5 {
+		t.Errorf("the re-admitted backend received %d calls, want 5", calls)
+	}
+}
+
+func TestUnreachableBackendIsAdmittedOnceServing(t *testing.T) {
+	// Reserve an address nobody listens on yet.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	_, servingAddress := startFakeServer(t, shared_types.HealthStatusServing)
+	balancer := newTestBalancer(t, servingAddress, address)
+	if balancer.isHealthy(address) {
+		t.Fatal("an unreachable backend starts out healthy")
+	}
+
+	server := &fakeServer{status: shared_types.HealthStatusServing}
+	rpcServer := rpc.NewServer()
+	rpcServer.RegisterName("WordCountServer", server)
+	listener, err = net.Listen("tcp", address)
+	if err != nil {
+		t.Skip("the reserved port was taken in the meantime:", err)
+	}
+	defer listener.Close()
+	go rpcServer.Accept(listener)
+
+	waitFor(t, "the backend to be admitted", func() bool { return balancer.isHealthy(address) })
+}
+
+func TestBrokenConnectionIsReplaced(t *testing.T) {
+	server, address := startFakeServer(t, shared_types.HealthStatusServing)
+	// The health checker never runs, only the calls themselves can notice the broken connection.
+	balancer := newTestBalancerWith(t, Config{Addresses: []string{address}, PoolSize: 2, HealthCheckInterval: time.Hour})
+	compute(t, balancer, 2)
+
+	server.kill(0)
+	failures := 0
+	for i := 0; i < 100; i++ {
+		if err := balancer.Compute(&shared_types.WordCountRequest{Content: "a b"}, new(shared_types.WordCountReply)); err != nil {
+			failures++
+		}
+	}
+	// Only the call which found the connection broken may fail, the pool must not keep handing it out.
+	if failures > 1 {
+		t.Errorf("%d of 100 calls failed after one pooled connection was killed", failures)
+	}
+	if !balancer.isHealthy(address) {
+		t.Error("the backend was ejected")
+	}
+	if accepted := server.accepted(); accepted != 3 {
+		t.Errorf("the server accepted %d connections, want 3", accepted)
+	}
+}
+
+func TestHealthCheckReplacesEveryBrokenConnection(t *testing.T) {
+	server, address := startFakeServer(t, shared_types.HealthStatusServing)
+	balancer := newTestBalancerWith(t, Config{Addresses: []string{address}, PoolSize: 3})
+
+	// Not the first connection of the pool, every one of them has to be checked.
+	server.kill(2)
+	waitFor(t, "the connection to be replaced", func() bool { return server.accepted() == 4 })
+	compute(t, balancer, 30)
+	if !balancer.isHealthy(address) {
+		t.Error("the backend was ejected")
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	_, address := startFakeServer(t, shared_types.HealthStatusServing)
+	balancer := newTestBalancerWith(t, Config{Addresses: []string{address}, CallTimeout: 50 * time.Millisecond})
+
+	start := time.Now()
+	err := balancer.Call("WordCountServer.Hang", &shared_types.WordCountRequest{}, new(shared_types.WordCountReply))
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("Call to a hanging backend returned %v, want ErrTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Call returned after %s, want about the 50ms timeout", elapsed)
+	}
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	shared_types "rpc/shared_types"
+	"sync"
+	"time"
+)
+
+// Key identifies a word count request by the hash of its content and the tokenize options, since the same
+// content counted with different options gives a different result.
+func Key(request *shared_types.WordCountRequest) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%t:%t:", request.Options.IgnoreCase, request.Options.TrimPunctuation)
+	hash.Write([]byte(request.Content))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type entry struct {
+	key       string
+	counts    map[string]int
+	expiresAt time.Time
+}
+
+type Stats struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
+}
+
+// Cache is a least recently used cache of word counts, bounded by the number of entries and by the time
+// an entry is allowed to live. The cached maps are shared between callers and must not be modified.
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	order      *list.List // Front is the most recently used entry.
+	items      map[string]*list.Element
+	hits       uint64
+	misses     uint64
+	now        func() time.Time
+}
+
+// New creates a cache holding at most maxEntries results, each for at most ttl. A zero ttl never expires entries.
+func New(maxEntries int, ttl time.Duration) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		order:      list.New(),
+		items:      make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+func (cache *Cache) Get(key string) (map[string]int, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	element, ok := cache.items[key]
+	if !ok {
+		cache.misses++
+		return nil, false
+	}
+	cached := element.Value.(*entry)
+	if cache.ttl > 0 && cache.now().After(cached.expiresAt) {
+		cache.removeElement(element)
+		cache.misses++
+		return nil, false
+	}
+	cache.order.MoveToFront(element)
+	cache.hits++
+	return cached.counts, true
+}
+
+func (cache *Cache) Add(key string, counts map[string]int) {
+	if cache.maxEntries <= 0 {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	expiresAt := cache.now().Add(cache.ttl)
+	if element, ok := cache.items[key]; ok {
+		cached := element.Value.(*entry)
+		cached.counts = counts
+		cached.expiresAt = expiresAt
+		cache.order.MoveToFront(element)
+		return
+	}
+	cache.items[key] = cache.order.PushFront(&entry{key: key, counts: counts, expiresAt: expiresAt})
+	for cache.order.Len() > cache.maxEntries {
+		cache.removeElement(cache.order.Back())
+	}
+}
+
+// removeElement must be called with the cache lock held.
+func (cache *Cache) removeElement(element *list.Element) {
+	cache.order.Remove(element)
+	delete(cache.items, element.Value.(*entry).key)
+}
+
+func (cache *Cache) Stats() Stats {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return Stats{Hits: cache.hits, Misses: cache.misses, Entries: cache.order.Len()}
+}
+
+func (cache *Cache) ResetStats() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.hits = 0
+	cache.misses = 0
+}
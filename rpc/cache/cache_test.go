@@ -0,0 +1,141 @@
+package cache
+
+import (
+	shared_types "rpc/shared_types"
+	"testing"
+	"time"
+)
+
+// newTestCache returns a cache whose clock only moves when the returned function is called.
+func newTestCache(maxEntries int, ttl time.Duration) (*Cache, func(time.Duration)) {
+	cache := New(maxEntries, ttl)
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+	return cache, func(d time.Duration) { now = now.Add(d) }
+}
+
+func counts(word string) map[string]int {
+	return map[string]int{word: 1}
+}
+
+func TestEvictsTheLeastRecentlyUsedEntry(t *testing.T) {
+	cache, _ := newTestCache(2, 0)
+	cache.Add("a", counts("a"))
+	cache.Add("b", counts("b"))
+	cache.Add("c", counts("c"))
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("the oldest entry was kept beyond maxEntries")
+	}
+	for _, key := range []string{"b", "c"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("entry %q was evicted", key)
+		}
+	}
+	if entries := cache.Stats().Entries; entries != 2 {
+		t.Errorf("Entries = %d, want 2", entries)
+	}
+}
+
+func TestGetUpdatesRecency(t *testing.T) {
+	cache, _ := newTestCache(2, 0)
+	cache.Add("a", counts("a"))
+	cache.Add("b", counts("b"))
+	// Reading "a" makes "b" the least recently used entry.
+	cache.Get("a")
+	cache.Add("c", counts("c"))
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("the least recently used entry was kept")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("the recently read entry was evicted")
+	}
+}
+
+func TestAddReplacesAnExistingEntry(t *testing.T) {
+	cache, _ := newTestCache(2, 0)
+	cache.Add("a", counts("a"))
+	cache.Add("b", counts("b"))
+	cache.Add("a", counts("new"))
+	cache.Add("c", counts("c"))
+
+	got, ok := cache.Get("a")
+	if !ok || got["new"] != 1 {
+		t.Errorf("Get(a) = %v, %t, want the replaced counts", got, ok)
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Error("replacing an entry did not make it the most recently used one")
+	}
+}
+
+func TestEntriesExpireAfterTheTTL(t *testing.T) {
+	cache, advance := newTestCache(10, time.Minute)
+	cache.Add("a", counts("a"))
+
+	advance(time.Minute)
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("the entry expired before its TTL passed")
+	}
+	advance(time.Nanosecond)
+	if _, ok := cache.Get("a"); ok {
+		t.Fatal("the entry was returned after its TTL passed")
+	}
+	if entries := cache.Stats().Entries; entries != 0 {
+		t.Errorf("Entries = %d, want the expired entry removed", entries)
+	}
+}
+
+func TestZeroTTLNeverExpires(t *testing.T) {
+	cache, advance := newTestCache(10, 0)
+	cache.Add("a", counts("a"))
+	advance(24 * time.Hour)
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("an entry expired although the TTL is zero")
+	}
+}
+
+func TestZeroMaxEntriesDisablesTheCache(t *testing.T) {
+	cache, _ := newTestCache(0, 0)
+	cache.Add("a", counts("a"))
+	if _, ok := cache.Get("a"); ok {
+		t.Error("a cache without room returned an entry")
+	}
+}
+
+func TestStats(t *testing.T) {
+	cache, _ := newTestCache(10, 0)
+	cache.Add("a", counts("a"))
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("b")
+	if stats := cache.Stats(); stats != (Stats{Hits: 2, Misses: 1, Entries: 1}) {
+		t.Errorf("Stats() = %+v, want 2 hits, 1 miss and 1 entry", stats)
+	}
+	cache.ResetStats()
+	if stats := cache.Stats(); stats != (Stats{Entries: 1}) {
+		t.Errorf("Stats() = %+v after ResetStats, want only the entry", stats)
+	}
+}
+
+func TestKey(t *testing.T) {
+	base := shared_types.WordCountRequest{Content: "Hello, hello!"}
+	tests := []struct {
+		name    string
+		request shared_types.WordCountRequest
+		same    bool
+	}{
+		{"same request", base, true},
+		{"NoCache does not change the result", shared_types.WordCountRequest{Content: base.Content, NoCache: true}, true},
+		{"different content", shared_types.WordCountRequest{Content: "Hello"}, false},
+		{"ignore case", shared_types.WordCountRequest{Content: base.Content, Options: shared_types.TokenizeOptions{IgnoreCase: true}}, false},
+		{"trim punctuation", shared_types.WordCountRequest{Content: base.Content, Options: shared_types.TokenizeOptions{TrimPunctuation: true}}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if same := Key(&base) == Key(&test.request); same != test.same {
+				t.Errorf("keys equal = %t, want %t", same, test.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package harness
+
+import (
+	"rpc/balancer"
+	"rpc/wordcount"
+)
+
+// Harness runs a word count server and a client connected to it inside the current process, for checks which
+// exercise the service end to end without any fixed ports.
+type Harness struct {
+	Server *wordcount.WordCountServer
+	Client *balancer.Balancer
+}
+
+// Start brings up a server with the given config, listening on an ephemeral port unless config.Address is set,
+// and connects a balancer to it.
+func Start(config wordcount.Config, clientConfig balancer.Config) (*Harness, error) {
+	if config.Address == "" {
+		config.Address = "127.0.0.1:0"
+	}
+	server := wordcount.NewServer(config)
+	if err := server.Listen(); err != nil {
+		return nil, err
+	}
+
+	clientConfig.Addresses = []string{server.Address()}
+	client, err := balancer.New(clientConfig)
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	return &Harness{Server: server, Client: client}, nil
+}
+
+func (harness *Harness) Close() {
+	harness.Client.Close()
+	harness.Server.Close()
+}
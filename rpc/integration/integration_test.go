@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"fmt"
+	"maps"
+	"rpc/balancer"
+	"rpc/cache"
+	"rpc/harness"
+	shared_types "rpc/shared_types"
+	"rpc/wordcount"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Runs the word count service end to end in a single process. Run it with the race detector to also check the
+// concurrent calls:
+// -> go test -race ./integration
+
+type check struct {
+	name     string
+	request  shared_types.WordCountRequest
+	expected map[string]int
+}
+
+func largeInput() (string, map[string]int) {
+	var builder strings.Builder
+	expected := make(map[string]int)
+	for i := 0; i < 200000; i++ {
+		word := fmt.Sprintf("w%d", i%1000)
+		builder.WriteString(word)
+		builder.WriteByte(' ')
+		expected[word]++
+	}
+	return builder.String(), expected
+}
+
+func checks() []check {
+	large, largeExpected := largeInput()
+	return []check{
+		{name: "empty content", request: shared_types.WordCountRequest{Content: ""}, expected: map[string]int{}},
+		{name: "whitespace only", request: shared_types.WordCountRequest{Content: " \t\n\r  　 "}, expected: map[string]int{}},
+		{name: "repeated words", request: shared_types.WordCountRequest{Content: "a b a c b a"},
+			expected: map[string]int{"a": 3, "b": 2, "c": 1}},
+		{name: "unicode words", request: shared_types.WordCountRequest{Content: "héllo 世界 héllo Привет мир"},
+			expected: map[string]int{"héllo": 2, "世界": 1, "Привет": 1, "мир": 1}},
+		{name: "unicode ignore case", request: shared_types.WordCountRequest{Content: "ПРИВЕТ привет Straße STRASSE",
+			Options: shared_types.TokenizeOptions{IgnoreCase: true}},
+			expected: map[string]int{"привет": 2, "straße": 1, "strasse": 1}},
+		{name: "trim punctuation", request: shared_types.WordCountRequest{Content: "day. day! ... «day»",
+			Options: shared_types.TokenizeOptions{TrimPunctuation: true}},
+			expected: map[string]int{"day": 3}},
+		{name: "large input", request: shared_types.WordCountRequest{Content: large}, expected: largeExpected},
+	}
+}
+
+func run(client *balancer.Balancer, check check) error {
+	request := check.request
+	request.Version = shared_types.CurrentVersion
+	reply := new(shared_types.WordCountReply)
+	if err := client.Compute(&request, reply); err != nil {
+		return err
+	}
+	// gob does not send empty maps, so an empty result arrives as a nil map.
+	if len(reply.Counts) != len(check.expected) || (len(check.expected) > 0 && !maps.Equal(reply.Counts, check.expected)) {
+		return fmt.Errorf("expected %d distinct words, got %d: %v", len(check.expected), len(reply.Counts), truncate(reply.Counts))
+	}
+	if reply.Version != shared_types.CurrentVersion {
+		return fmt.Errorf("expected reply version %d, got %d", shared_types.CurrentVersion, reply.Version)
+	}
+	return nil
+}
+
+func truncate(counts map[string]int) string {
+	text := fmt.Sprint(counts)
+	if len(text) > 200 {
+		return text[:200] + "..."
+	}
+	return text
+}
+
+func startHarness(t *testing.T) *harness.Harness {
+	t.Helper()
+	testHarness, err := harness.Start(
+		wordcount.Config{Cache: cache.New(128, time.Minute)},
+		balancer.Config{PoolSize: 4},
+	)
+	if err != nil {
+		t.Fatal("unable to start the harness:", err)
+	}
+	t.Cleanup(testHarness.Close)
+	return testHarness
+}
+
+func TestWordCount(t *testing.T) {
+	client := startHarness(t).Client
+	checks := checks()
+
+	for _, check := range checks {
+		check := check
+		t.Run(check.name, func(t *testing.T) {
+			if err := run(client, check); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+
+	t.Run("concurrent calls", func(t *testing.T) {
+		const workers = 32
+		const callsPerWorker = 25
+
+		var wg sync.WaitGroup
+		for worker := 0; worker < workers; worker++ {
+			wg.Add(1)
+			go func(worker int) {
+				defer wg.Done()
+				for call := 0; call < callsPerWorker; call++ {
+					check := checks[(worker+call)%len(checks)]
+					// Half of the calls bypass the cache, so both paths run concurrently.
+					check.request.NoCache = call%2 == 0
+					if err := run(client, check); err != nil {
+						t.Errorf("%s: %s", check.name, err)
+					}
+				}
+			}(worker)
+		}
+		wg.Wait()
+	})
+}
+
+func TestCacheHits(t *testing.T) {
+	client := startHarness(t).Client
+	if err := client.Call("WordCountServer.CacheStats", &shared_types.CacheStatsRequest{Reset: true}, new(shared_types.CacheStatsReply)); err != nil {
+		t.Fatal(err)
+	}
+	request := &shared_types.WordCountRequest{Content: "cache me cache me"}
+	for i := 0; i < 2; i++ {
+		if err := client.Compute(request, new(shared_types.WordCountReply)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := new(shared_types.CacheStatsReply)
+	if err := client.Call("WordCountServer.CacheStats", &shared_types.CacheStatsRequest{}, stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+}
@@ -0,0 +1,285 @@
+package interceptor
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/rpc"
+	"sync"
+	"time"
+)
+
+// net/rpc does not provide any hook around the method calls, but every call goes through the codec: the request is
+// read with ReadRequestHeader and ReadRequestBody, and the result is written with WriteResponse. NewServerCodec returns the
+// gob codec of net/rpc which additionally remembers when each request arrived, so the interceptors can be run with
+// the method name, size, duration and error of the call once its response is written.
+
+var ErrRequestTooLarge = errors.New("interceptor: request exceeds the maximum size")
+
+// CallInfo describes a single completed call.
+type CallInfo struct {
+	ServiceMethod string
+	Seq           uint64
+	// Number of bytes read from the connection for the request header and body.
+	RequestBytes int64
+	Start        time.Time
+	Duration     time.Duration
+	// Error returned by the method, empty if the call succeeded.
+	Error string
+}
+
+// Interceptor is run after every call. Interceptors are run by the connection's sending go routine, so they must
+// be quick and safe for concurrent use across connections.
+type Interceptor func(call *CallInfo)
+
+type Options struct {
+	// Requests larger than this are answered with ErrRequestTooLarge, 0 means no limit. The connection stays usable
+	// after a request body over the limit, but a header over the limit closes it, as net/rpc gives up on a
+	// connection whose request header can not be read.
+	MaxRequestBytes int64
+	Interceptors    []Interceptor
+}
+
+// gob frames every message with its length, encoded as a gob uint, and starts it with the type id, a gob int which
+// is negative for the type definitions sent ahead of the first value of a type.
+const maxGobUintBytes = 9
+
+// countingReader counts the bytes handed to the gob decoder. It implements io.ByteReader, so the decoder uses it
+// directly instead of adding its own buffering, which keeps the count exact.
+//
+// The limit is checked once per gob message, before the decoder reads any of it. A value over the limit is skipped
+// as a whole, so the decoder never sees it and stays in sync with the stream: the request is answered with an error
+// and the connection can be used for the next one. A type definition can not be skipped like that, as the client
+// sends it only once and the decoder needs it for every later value of that type, so from then on the stream is
+// out of sync and the connection is closed.
+type countingReader struct {
+	reader *bufio.Reader
+	count  int64
+	limit  int64
+	// Bytes left of the message being read, 0 between messages.
+	messageLeft int64
+	// Set once a type definition over the limit was seen.
+	broken bool
+}
+
+// allowed returns how many of the wanted bytes can be read without passing the end of the current message.
+func (countingReader *countingReader) allowed(want int) (int, error) {
+	if countingReader.broken {
+		return 0, ErrRequestTooLarge
+	}
+	if countingReader.messageLeft == 0 {
+		if err := countingReader.startMessage(); err != nil {
+			return 0, err
+		}
+	}
+	if int64(want) > countingReader.messageLeft {
+		return int(countingReader.messageLeft), nil
+	}
+	return want, nil
+}
+
+// startMessage peeks at the length and type id of the next message and skips it when it is a value over the limit.
+func (countingReader *countingReader) startMessage() error {
+	length, prefix, err := countingReader.peekUint(0)
+	if err != nil {
+		return err
+	}
+	// gob refuses messages of 1GB or more itself, checking that here keeps the size from overflowing.
+	if length >= 1<<30 {
+		countingReader.broken = true
+		return ErrRequestTooLarge
+	}
+	size := int64(prefix) + int64(length)
+	if countingReader.limit <= 0 || countingReader.count+size <= countingReader.limit {
+		countingReader.messageLeft = size
+		return nil
+	}
+
+	typeID, _, err := countingReader.peekUint(prefix)
+	if err != nil {
+		return err
+	}
+	// The lowest bit of a gob int is its sign.
+	if typeID&1 == 1 {
+		countingReader.broken = true
+		return ErrRequestTooLarge
+	}
+	discarded, err := countingReader.reader.Discard(int(size))
+	countingReader.count += int64(discarded)
+	if err != nil {
+		return err
+	}
+	return ErrRequestTooLarge
+}
+
+// peekUint decodes the gob uint starting offset bytes ahead without consuming it, and returns its encoded size.
+func (countingReader *countingReader) peekUint(offset int) (uint64, int, error) {
+	buf, err := countingReader.reader.Peek(offset + 1)
+	if err != nil {
+		return 0, 0, peekError(err, offset)
+	}
+	first := buf[offset]
+	if first < 0x80 {
+		return uint64(first), 1, nil
+	}
+	// Larger values are a byte holding the negated count of the big endian bytes that follow.
+	n := -int(int8(first))
+	if n > maxGobUintBytes-1 {
+		return 0, 0, errors.New("interceptor: invalid gob message length")
+	}
+	buf, err = countingReader.reader.Peek(offset + 1 + n)
+	if err != nil {
+		return 0, 0, peekError(err, offset+1)
+	}
+	var value uint64
+	for _, b := range buf[offset+1:] {
+		value = value<<8 | uint64(b)
+	}
+	return value, 1 + n, nil
+}
+
+// peekError keeps io.EOF only for a connection closed between two messages, as the decoder and net/rpc treat it as
+// a clean shutdown.
+func peekError(err error, offset int) error {
+	if err == io.EOF && offset > 0 {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (countingReader *countingReader) Read(p []byte) (int, error) {
+	allowed, err := countingReader.allowed(len(p))
+	if err != nil {
+		return 0, err
+	}
+	n, err := countingReader.reader.Read(p[:allowed])
+	countingReader.count += int64(n)
+	countingReader.messageLeft -= int64(n)
+	return n, err
+}
+
+func (countingReader *countingReader) ReadByte() (byte, error) {
+	if _, err := countingReader.allowed(1); err != nil {
+		return 0, err
+	}
+	b, err := countingReader.reader.ReadByte()
+	if err == nil {
+		countingReader.count++
+		countingReader.messageLeft--
+	}
+	return b, err
+}
+
+type pendingCall struct {
+	serviceMethod string
+	requestBytes  int64
+	start         time.Time
+}
+
+type serverCodec struct {
+	rwc     io.ReadWriteCloser
+	reader  *countingReader
+	dec     *gob.Decoder
+	enc     *gob.Encoder
+	encBuf  *bufio.Writer
+	options Options
+	closed  bool
+
+	// Requests are read by the server's reading go routine and answered by the sending go routine.
+	mu      sync.Mutex
+	pending map[uint64]pendingCall
+	// Sequence number of the request currently being read.
+	currentSeq uint64
+}
+
+func NewServerCodec(conn io.ReadWriteCloser, options Options) rpc.ServerCodec {
+	reader := &countingReader{reader: bufio.NewReader(conn)}
+	buf := bufio.NewWriter(conn)
+	return &serverCodec{
+		rwc:     conn,
+		reader:  reader,
+		dec:     gob.NewDecoder(reader),
+		enc:     gob.NewEncoder(buf),
+		encBuf:  buf,
+		options: options,
+		pending: make(map[uint64]pendingCall),
+	}
+}
+
+func (codec *serverCodec) ReadRequestHeader(request *rpc.Request) error {
+	codec.reader.count = 0
+	codec.reader.limit = codec.options.MaxRequestBytes
+	if err := codec.dec.Decode(request); err != nil {
+		return err
+	}
+
+	codec.mu.Lock()
+	defer codec.mu.Unlock()
+	codec.pending[request.Seq] = pendingCall{serviceMethod: request.ServiceMethod, start: time.Now()}
+	codec.currentSeq = request.Seq
+	return nil
+}
+
+func (codec *serverCodec) ReadRequestBody(body any) error {
+	err := codec.dec.Decode(body)
+
+	codec.mu.Lock()
+	if call, ok := codec.pending[codec.currentSeq]; ok {
+		call.requestBytes = codec.reader.count
+		codec.pending[codec.currentSeq] = call
+	}
+	codec.mu.Unlock()
+
+	if errors.Is(err, ErrRequestTooLarge) {
+		return fmt.Errorf("%w (limit %d bytes)", ErrRequestTooLarge, codec.options.MaxRequestBytes)
+	}
+	return err
+}
+
+func (codec *serverCodec) WriteResponse(response *rpc.Response, body any) (err error) {
+	codec.mu.Lock()
+	call, ok := codec.pending[response.Seq]
+	delete(codec.pending, response.Seq)
+	codec.mu.Unlock()
+
+	if ok {
+		info := &CallInfo{
+			ServiceMethod: call.serviceMethod,
+			Seq:           response.Seq,
+			RequestBytes:  call.requestBytes,
+			Start:         call.start,
+			Duration:      time.Since(call.start),
+			Error:         response.Error,
+		}
+		for _, interceptor := range codec.options.Interceptors {
+			interceptor(info)
+		}
+	}
+
+	if err = codec.enc.Encode(response); err != nil {
+		if codec.encBuf.Flush() == nil {
+			log.Println("rpc: gob error encoding response:", err)
+			codec.Close()
+		}
+		return
+	}
+	if err = codec.enc.Encode(body); err != nil {
+		if codec.encBuf.Flush() == nil {
+			log.Println("rpc: gob error encoding body:", err)
+			codec.Close()
+		}
+		return
+	}
+	return codec.encBuf.Flush()
+}
+
+func (codec *serverCodec) Close() error {
+	if codec.closed {
+		return nil
+	}
+	codec.closed = true
+	return codec.rwc.Close()
+}
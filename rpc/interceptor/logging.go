@@ -0,0 +1,20 @@
+package interceptor
+
+import "log/slog"
+
+// Logging writes one structured log line per call.
+func Logging(logger *slog.Logger) Interceptor {
+	return func(call *CallInfo) {
+		attributes := []any{
+			slog.String("method", call.ServiceMethod),
+			slog.Uint64("seq", call.Seq),
+			slog.Duration("duration", call.Duration),
+			slog.Int64("request_bytes", call.RequestBytes),
+		}
+		if call.Error != "" {
+			logger.Error("rpc call failed", append(attributes, slog.String("error", call.Error))...)
+			return
+		}
+		logger.Info("rpc call", attributes...)
+	}
+}
@@ -0,0 +1,44 @@
+package interceptor
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestLogging(t *testing.T) {
+	tests := []struct {
+		name string
+		call CallInfo
+		want string
+	}{
+		{
+			"success",
+			CallInfo{ServiceMethod: "Echo.Echo", Seq: 7, RequestBytes: 42, Duration: 3 * time.Millisecond},
+			`level=INFO msg="rpc call" method=Echo.Echo seq=7 duration=3ms request_bytes=42` + "\n",
+		},
+		{
+			"failure",
+			CallInfo{ServiceMethod: "Echo.Fail", Seq: 8, RequestBytes: 1, Duration: time.Second, Error: "failed"},
+			`level=ERROR msg="rpc call failed" method=Echo.Fail seq=8 duration=1s request_bytes=1 error=failed` + "\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+				ReplaceAttr: func(groups []string, attribute slog.Attr) slog.Attr {
+					if attribute.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+					return attribute
+				},
+			}))
+			Logging(logger)(&test.call)
+			if got := buf.String(); got != test.want {
+				t.Errorf("logged %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package interceptor
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds in seconds of the call duration histogram.
+var DefaultBuckets = []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+type histogram struct {
+	// counts[i] holds the number of observations <= buckets[i], like the cumulative buckets of Prometheus.
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+type methodMetrics struct {
+	calls        uint64
+	errors       uint64
+	requestBytes uint64
+	duration     histogram
+}
+
+// Metrics collects per method counters and a duration histogram, and exposes them in the Prometheus text format.
+type Metrics struct {
+	mu      sync.Mutex
+	buckets []float64
+	methods map[string]*methodMetrics
+}
+
+func NewMetrics(buckets []float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Metrics{buckets: buckets, methods: make(map[string]*methodMetrics)}
+}
+
+// Interceptor records every call into the metrics.
+func (metrics *Metrics) Interceptor() Interceptor {
+	return func(call *CallInfo) {
+		metrics.mu.Lock()
+		defer metrics.mu.Unlock()
+
+		method, ok := metrics.methods[call.ServiceMethod]
+		if !ok {
+			method = &methodMetrics{duration: histogram{counts: make([]uint64, len(metrics.buckets))}}
+			metrics.methods[call.ServiceMethod] = method
+		}
+		method.calls++
+		if call.Error != "" {
+			method.errors++
+		}
+		method.requestBytes += uint64(call.RequestBytes)
+
+		seconds := call.Duration.Seconds()
+		for i, bound := range metrics.buckets {
+			if seconds <= bound {
+				method.duration.counts[i]++
+			}
+		}
+		method.duration.sum += seconds
+		method.duration.count++
+	}
+}
+
+func (metrics *Metrics) WritePrometheus(w io.Writer) {
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	names := make([]string, 0, len(metrics.methods))
+	for name := range metrics.methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "# TYPE rpc_calls_total counter")
+	for _, name := range names {
+		fmt.Fprintf(w, "rpc_calls_total{method=%q} %d\n", name, metrics.methods[name].calls)
+	}
+	fmt.Fprintln(w, "# TYPE rpc_errors_total counter")
+	for _, name := range names {
+		fmt.Fprintf(w, "rpc_errors_total{method=%q} %d\n", name, metrics.methods[name].errors)
+	}
+	fmt.Fprintln(w, "# TYPE rpc_request_bytes_total counter")
+	for _, name := range names {
+		fmt.Fprintf(w, "rpc_request_bytes_total{method=%q} %d\n", name, metrics.methods[name].requestBytes)
+	}
+	fmt.Fprintln(w, "# TYPE rpc_call_duration_seconds histogram")
+	for _, name := range names {
+		duration := metrics.methods[name].duration
+		for i, bound := range metrics.buckets {
+			fmt.Fprintf(w, "rpc_call_duration_seconds_bucket{method=%q,le=\"%g\"} %d\n", name, bound, duration.counts[i])
+		}
+		fmt.Fprintf(w, "rpc_call_duration_seconds_bucket{method=%q,le=\"+Inf\"} %d\n", name, duration.count)
+		fmt.Fprintf(w, "rpc_call_duration_seconds_sum{method=%q} %g\n", name, duration.sum)
+		fmt.Fprintf(w, "rpc_call_duration_seconds_count{method=%q} %d\n", name, duration.count)
+	}
+}
+
+// ServeHTTP lets the metrics be scraped, e.g. by registering them at /metrics.
+func (metrics *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	metrics.WritePrometheus(w)
+}
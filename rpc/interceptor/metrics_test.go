@@ -0,0 +1,71 @@
+package interceptor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsCounters(t *testing.T) {
+	metrics := NewMetrics([]float64{0.5, 0.1})
+	record := metrics.Interceptor()
+	record(&CallInfo{ServiceMethod: "Echo.Echo", RequestBytes: 10, Duration: 50 * time.Millisecond})
+	record(&CallInfo{ServiceMethod: "Echo.Echo", RequestBytes: 20, Duration: 200 * time.Millisecond})
+	record(&CallInfo{ServiceMethod: "Echo.Echo", RequestBytes: 30, Duration: time.Second, Error: "failed"})
+	record(&CallInfo{ServiceMethod: "Echo.Fail", Duration: time.Millisecond, Error: "failed"})
+
+	var buf bytes.Buffer
+	metrics.WritePrometheus(&buf)
+	// The buckets are sorted and cumulative, and the methods are sorted by name.
+	want := `# TYPE rpc_calls_total counter
+rpc_calls_total{method="Echo.Echo"} 3
+rpc_calls_total{method="Echo.Fail"} 1
+# TYPE rpc_errors_total counter
+rpc_errors_total{method="Echo.Echo"} 1
+rpc_errors_total{method="Echo.Fail"} 1
+# TYPE rpc_request_bytes_total counter
+rpc_request_bytes_total{method="Echo.Echo"} 60
+rpc_request_bytes_total{method="Echo.Fail"} 0
+# TYPE rpc_call_duration_seconds histogram
+rpc_call_duration_seconds_bucket{method="Echo.Echo",le="0.1"} 1
+rpc_call_duration_seconds_bucket{method="Echo.Echo",le="0.5"} 2
+rpc_call_duration_seconds_bucket{method="Echo.Echo",le="+Inf"} 3
+rpc_call_duration_seconds_sum{method="Echo.Echo"} 1.25
+rpc_call_duration_seconds_count{method="Echo.Echo"} 3
+rpc_call_duration_seconds_bucket{method="Echo.Fail",le="0.1"} 1
+rpc_call_duration_seconds_bucket{method="Echo.Fail",le="0.5"} 1
+rpc_call_duration_seconds_bucket{method="Echo.Fail",le="+Inf"} 1
+rpc_call_duration_seconds_sum{method="Echo.Fail"} 0.001
+rpc_call_duration_seconds_count{method="Echo.Fail"} 1
+`
+	if got := buf.String(); got != want {
+		t.Errorf("WritePrometheus wrote\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMetricsCountServedCalls(t *testing.T) {
+	metrics := NewMetrics(nil)
+	client := newTestClient(t, Options{MaxRequestBytes: 256, Interceptors: []Interceptor{metrics.Interceptor()}})
+	echo(client, "a")
+	echo(client, "b")
+	echo(client, strings.Repeat("x", 1000))
+	client.Call("Echo.Panic", &EchoArgs{}, &EchoReply{})
+
+	var buf bytes.Buffer
+	metrics.WritePrometheus(&buf)
+	for _, line := range []string{
+		`rpc_calls_total{method="Echo.Echo"} 3`,
+		`rpc_errors_total{method="Echo.Echo"} 1`,
+		`rpc_calls_total{method="Echo.Panic"} 1`,
+		`rpc_errors_total{method="Echo.Panic"} 1`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("metrics are missing %q:\n%s", line, buf.String())
+		}
+	}
+	// The oversized request is counted with the bytes skipped for it.
+	if strings.Contains(buf.String(), `rpc_request_bytes_total{method="Echo.Echo"} 0`) {
+		t.Errorf("no request bytes were counted:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,236 @@
+package interceptor
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"io"
+	"log"
+	"log/slog"
+	"net"
+	"net/rpc"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// net/rpc runs every method in its own go routine and calls it through reflection, so neither the codec nor the
+// caller of ServeCodec can recover a panic inside a method, and an unrecovered panic takes the whole process down.
+// Server serves the same wire protocol with the same method rules as rpc.Server, but calls the methods itself and
+// turns a panic into an error returned to the caller, so the services do not have to recover in every method.
+
+var typeOfError = reflect.TypeOf((*error)(nil)).Elem()
+
+// A value sent as the reply of a failed call, the client never decodes it as the response carries the error.
+var invalidRequest = struct{}{}
+
+type method struct {
+	function  reflect.Value
+	argType   reflect.Type
+	replyType reflect.Type
+}
+
+type service struct {
+	name     string
+	receiver reflect.Value
+	methods  map[string]*method
+}
+
+type Server struct {
+	options  Options
+	mu       sync.RWMutex
+	services map[string]*service
+}
+
+// NewServer returns a server which serves every connection with the codec of NewServerCodec and the given options.
+func NewServer(options Options) *Server {
+	return &Server{options: options, services: make(map[string]*service)}
+}
+
+// Register publishes the methods of receiver under the name of its concrete type, like rpc.Server.Register.
+func (server *Server) Register(receiver any) error {
+	return server.register(receiver, "", false)
+}
+
+// RegisterName is like Register but publishes the methods under the given name.
+func (server *Server) RegisterName(name string, receiver any) error {
+	return server.register(receiver, name, true)
+}
+
+func (server *Server) register(receiver any, name string, useName bool) error {
+	service := &service{receiver: reflect.ValueOf(receiver), methods: make(map[string]*method)}
+	if !useName {
+		name = reflect.Indirect(service.receiver).Type().Name()
+	}
+	if name == "" {
+		return fmt.Errorf("interceptor: no service name for type %s", service.receiver.Type())
+	}
+	if !useName && !token.IsExported(name) {
+		return fmt.Errorf("interceptor: type %s is not exported", name)
+	}
+	service.name = name
+
+	receiverType := service.receiver.Type()
+	for i := 0; i < receiverType.NumMethod(); i++ {
+		if method := suitableMethod(receiverType.Method(i)); method != nil {
+			service.methods[receiverType.Method(i).Name] = method
+		}
+	}
+	if len(service.methods) == 0 {
+		return fmt.Errorf("interceptor: type %s has no exported methods of suitable type", name)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if _, ok := server.services[name]; ok {
+		return fmt.Errorf("interceptor: service already defined: %s", name)
+	}
+	server.services[name] = service
+	return nil
+}
+
+// suitableMethod applies the rules of net/rpc: func (t *T) Method(args T1, reply *T2) error, where T1 and T2 are
+// exported or builtin types. Other methods are skipped silently.
+func suitableMethod(reflectMethod reflect.Method) *method {
+	methodType := reflectMethod.Type
+	if !reflectMethod.IsExported() || methodType.NumIn() != 3 || methodType.NumOut() != 1 {
+		return nil
+	}
+	argType, replyType := methodType.In(1), methodType.In(2)
+	if !isExportedOrBuiltin(argType) || replyType.Kind() != reflect.Pointer || !isExportedOrBuiltin(replyType) {
+		return nil
+	}
+	if methodType.Out(0) != typeOfError {
+		return nil
+	}
+	return &method{function: reflectMethod.Func, argType: argType, replyType: replyType}
+}
+
+func isExportedOrBuiltin(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return token.IsExported(t.Name()) || t.PkgPath() == ""
+}
+
+// Accept serves every connection of the listener in its own go routine until the listener is closed.
+func (server *Server) Accept(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Print("rpc.Serve: accept:", err.Error())
+			}
+			return
+		}
+		go server.ServeConn(conn)
+	}
+}
+
+// ServeConn serves a single connection until the client hangs up.
+func (server *Server) ServeConn(conn io.ReadWriteCloser) {
+	server.ServeCodec(NewServerCodec(conn, server.options))
+}
+
+// ServeCodec reads requests from the codec and answers each of them from its own go routine, like
+// rpc.Server.ServeCodec. It returns after the connection broke and every call in flight was answered.
+func (server *Server) ServeCodec(codec rpc.ServerCodec) {
+	sending := new(sync.Mutex)
+	wg := new(sync.WaitGroup)
+	for {
+		var request rpc.Request
+		if err := codec.ReadRequestHeader(&request); err != nil {
+			if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				log.Println("rpc:", err)
+			}
+			break
+		}
+
+		service, method, err := server.lookup(request.ServiceMethod)
+		if err != nil {
+			// The body still has to be read, to get to the next request.
+			codec.ReadRequestBody(nil)
+			server.respond(codec, sending, &request, invalidRequest, err.Error())
+			continue
+		}
+
+		// The argument is decoded through a pointer, even when the method takes a value.
+		var arg reflect.Value
+		if method.argType.Kind() == reflect.Pointer {
+			arg = reflect.New(method.argType.Elem())
+		} else {
+			arg = reflect.New(method.argType)
+		}
+		if err := codec.ReadRequestBody(arg.Interface()); err != nil {
+			server.respond(codec, sending, &request, invalidRequest, err.Error())
+			continue
+		}
+		if method.argType.Kind() != reflect.Pointer {
+			arg = arg.Elem()
+		}
+
+		reply := reflect.New(method.replyType.Elem())
+		switch method.replyType.Elem().Kind() {
+		case reflect.Map:
+			reply.Elem().Set(reflect.MakeMap(method.replyType.Elem()))
+		case reflect.Slice:
+			reply.Elem().Set(reflect.MakeSlice(method.replyType.Elem(), 0, 0))
+		}
+
+		wg.Add(1)
+		go func(request rpc.Request) {
+			defer wg.Done()
+			if err := service.call(request.ServiceMethod, method, arg, reply); err != nil {
+				server.respond(codec, sending, &request, invalidRequest, err.Error())
+				return
+			}
+			server.respond(codec, sending, &request, reply.Interface(), "")
+		}(request)
+	}
+	// Calls in flight still write their responses, which fail once the connection is gone.
+	wg.Wait()
+	codec.Close()
+}
+
+func (server *Server) lookup(serviceMethod string) (*service, *method, error) {
+	serviceName, methodName, ok := strings.Cut(serviceMethod, ".")
+	if !ok {
+		return nil, nil, fmt.Errorf("rpc: service/method request ill-formed: %s", serviceMethod)
+	}
+	server.mu.RLock()
+	service := server.services[serviceName]
+	server.mu.RUnlock()
+	if service == nil {
+		return nil, nil, fmt.Errorf("rpc: can't find service %s", serviceMethod)
+	}
+	method := service.methods[methodName]
+	if method == nil {
+		return nil, nil, fmt.Errorf("rpc: can't find method %s", serviceMethod)
+	}
+	return service, method, nil
+}
+
+// call runs the method and turns a panic inside it into an error.
+func (service *service) call(serviceMethod string, method *method, arg, reply reflect.Value) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			slog.Error("rpc method panicked", slog.String("method", serviceMethod), slog.Any("panic", recovered),
+				slog.String("stack", string(debug.Stack())))
+			err = fmt.Errorf("internal error in %s: %v", serviceMethod, recovered)
+		}
+	}()
+	result := method.function.Call([]reflect.Value{service.receiver, arg, reply})
+	if err, ok := result[0].Interface().(error); ok && err != nil {
+		return err
+	}
+	return nil
+}
+
+func (server *Server) respond(codec rpc.ServerCodec, sending *sync.Mutex, request *rpc.Request, reply any, errorMessage string) {
+	response := rpc.Response{ServiceMethod: request.ServiceMethod, Seq: request.Seq, Error: errorMessage}
+	sending.Lock()
+	defer sending.Unlock()
+	// Like net/rpc, a failed write is not reported: the connection is broken and the reading loop ends with it.
+	codec.WriteResponse(&response, reply)
+}
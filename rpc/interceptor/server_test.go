@@ -0,0 +1,138 @@
+package interceptor
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"strings"
+	"testing"
+)
+
+type EchoArgs struct {
+	Data string
+}
+
+type EchoReply struct {
+	Data string
+}
+
+type Echo struct{}
+
+func (Echo) Echo(args *EchoArgs, reply *EchoReply) error {
+	reply.Data = args.Data
+	return nil
+}
+
+func (Echo) Fail(args *EchoArgs, reply *EchoReply) error {
+	return errors.New("failed")
+}
+
+func (Echo) Panic(args *EchoArgs, reply *EchoReply) error {
+	panic("boom")
+}
+
+// newTestClient serves Echo with the given options over an in-memory connection.
+func newTestClient(t *testing.T, options Options) *rpc.Client {
+	t.Helper()
+	server := NewServer(options)
+	if err := server.Register(Echo{}); err != nil {
+		t.Fatal(err)
+	}
+	serverConn, clientConn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		server.ServeConn(serverConn)
+		close(done)
+	}()
+	client := rpc.NewClient(clientConn)
+	t.Cleanup(func() {
+		client.Close()
+		<-done
+	})
+	return client
+}
+
+func echo(client *rpc.Client, data string) error {
+	var reply EchoReply
+	if err := client.Call("Echo.Echo", &EchoArgs{Data: data}, &reply); err != nil {
+		return err
+	}
+	if reply.Data != data {
+		return errors.New("the reply does not match the request")
+	}
+	return nil
+}
+
+func TestRequestOverTheLimitKeepsTheConnection(t *testing.T) {
+	client := newTestClient(t, Options{MaxRequestBytes: 256})
+	large := strings.Repeat("x", 1000)
+	// The first request also carries the type definitions, which must reach the decoder although its value does not.
+	for i, data := range []string{large, "small", large, large, "small"} {
+		err := echo(client, data)
+		if len(data) > 256 {
+			if err == nil || !strings.Contains(err.Error(), ErrRequestTooLarge.Error()) {
+				t.Errorf("request %d of %d bytes: err = %v, want %v", i, len(data), err, ErrRequestTooLarge)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("request %d after a request over the limit failed: %v", i, err)
+		}
+	}
+}
+
+func TestHeaderOverTheLimitClosesTheConnection(t *testing.T) {
+	client := newTestClient(t, Options{MaxRequestBytes: 8})
+	err := echo(client, "")
+	if err == nil {
+		t.Fatal("a header over the limit was served")
+	}
+	if err := echo(client, ""); !errors.Is(err, rpc.ErrShutdown) {
+		t.Errorf("second call err = %v, want %v", err, rpc.ErrShutdown)
+	}
+}
+
+func TestPanicIsReturnedAsError(t *testing.T) {
+	client := newTestClient(t, Options{})
+	var reply EchoReply
+	err := client.Call("Echo.Panic", &EchoArgs{}, &reply)
+	if err == nil || err.Error() != "internal error in Echo.Panic: boom" {
+		t.Errorf("err = %v, want the recovered panic", err)
+	}
+	if err := echo(client, "after the panic"); err != nil {
+		t.Errorf("call after the panic failed: %v", err)
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	client := newTestClient(t, Options{})
+	var reply EchoReply
+	for _, serviceMethod := range []string{"Echo.Missing", "Missing.Echo", "Echo"} {
+		if err := client.Call(serviceMethod, &EchoArgs{}, &reply); err == nil {
+			t.Errorf("%s was served", serviceMethod)
+		}
+	}
+	if err := echo(client, "still there"); err != nil {
+		t.Errorf("call after unknown methods failed: %v", err)
+	}
+}
+
+type unsuitable struct{}
+
+func (unsuitable) NoReply(args *EchoArgs) error { return nil }
+
+func TestRegisterErrors(t *testing.T) {
+	server := NewServer(Options{})
+	if err := server.Register(Echo{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := server.Register(Echo{}); err == nil {
+		t.Error("the same service was registered twice")
+	}
+	if err := server.Register(unsuitable{}); err == nil {
+		t.Error("an unexported type was registered")
+	}
+	if err := server.RegisterName("Unsuitable", unsuitable{}); err == nil {
+		t.Error("a type without suitable methods was registered")
+	}
+}
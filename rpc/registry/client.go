@@ -0,0 +1,169 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/rpc"
+	"time"
+)
+
+const (
+	// Every dial and call to the registry gives up after this long, so an unresponsive registry can not block a
+	// lookup or Announcer.Stop forever.
+	callTimeout = 5 * time.Second
+	// Lower bound of the heartbeat interval, in case the registry hands out a tiny TTL.
+	minHeartbeatInterval = 10 * time.Millisecond
+)
+
+func dial(registryAddress string, timeout time.Duration) (*rpc.Client, error) {
+	conn, err := net.DialTimeout("tcp", registryAddress, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return rpc.NewClient(conn), nil
+}
+
+// call is client.Call with a deadline. After a timeout the connection is out of sync with the registry and should
+// be closed.
+func call(client *rpc.Client, serviceMethod string, args any, reply any, timeout time.Duration) error {
+	pending := client.Go(serviceMethod, args, reply, make(chan *rpc.Call, 1))
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-pending.Done:
+		return pending.Error
+	case <-timer.C:
+		return fmt.Errorf("registry: %s timed out after %s", serviceMethod, timeout)
+	}
+}
+
+// heartbeatInterval is a third of the TTL, so two heartbeats can be lost before the instance expires.
+func heartbeatInterval(ttl time.Duration) time.Duration {
+	return max(ttl/3, minHeartbeatInterval)
+}
+
+// Lookup asks the registry at registryAddress for the live instances of service.
+func Lookup(registryAddress, service string) ([]string, error) {
+	client, err := dial(registryAddress, callTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	reply := new(LookupReply)
+	err = call(client, ServiceName+".Lookup", &LookupRequest{Service: service}, reply, callTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if len(reply.Addresses) == 0 {
+		return nil, fmt.Errorf("registry: no live instances of %s", service)
+	}
+	return reply.Addresses, nil
+}
+
+// Announcer keeps a service instance registered by sending heartbeats in the background.
+type Announcer struct {
+	registryAddress string
+	service         string
+	address         string
+	ttl             time.Duration
+	timeout         time.Duration
+	stop            chan struct{}
+	done            chan struct{}
+}
+
+// Announce registers address under service and heartbeats every third of the TTL. When the registry is restarted
+// or the instance expired, the instance registers again on the next heartbeat.
+func Announce(registryAddress, service, address string, ttl time.Duration) (*Announcer, error) {
+	announcer := &Announcer{
+		registryAddress: registryAddress,
+		service:         service,
+		address:         address,
+		ttl:             ttl,
+		timeout:         callTimeout,
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+
+	client, err := dial(registryAddress, announcer.timeout)
+	if err != nil {
+		return nil, err
+	}
+	if err := announcer.register(client); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	go announcer.heartbeatLoop(client)
+	return announcer, nil
+}
+
+func (announcer *Announcer) register(client *rpc.Client) error {
+	reply := new(RegisterReply)
+	args := &RegisterRequest{Service: announcer.service, Address: announcer.address, TTL: announcer.ttl}
+	if err := call(client, ServiceName+".Register", args, reply, announcer.timeout); err != nil {
+		return err
+	}
+	announcer.ttl = reply.TTL
+	return nil
+}
+
+func (announcer *Announcer) heartbeatLoop(client *rpc.Client) {
+	defer close(announcer.done)
+	defer func() {
+		if client != nil {
+			client.Close()
+		}
+	}()
+
+	ticker := time.NewTicker(heartbeatInterval(announcer.ttl))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-announcer.stop:
+			if client != nil {
+				args := &DeregisterRequest{Service: announcer.service, Address: announcer.address}
+				call(client, ServiceName+".Deregister", args, new(DeregisterReply), announcer.timeout)
+			}
+			return
+		case <-ticker.C:
+		}
+
+		if client == nil {
+			var err error
+			client, err = dial(announcer.registryAddress, announcer.timeout)
+			if err != nil {
+				log.Println("registry: unable to reach the registry", err.Error())
+				continue
+			}
+		}
+
+		args := &HeartbeatRequest{Service: announcer.service, Address: announcer.address}
+		err := call(client, ServiceName+".Heartbeat", args, new(HeartbeatReply), announcer.timeout)
+		// Errors of the registry itself come back as rpc.ServerError, which only keeps the error message.
+		if err != nil && err.Error() == ErrNotRegistered.Error() {
+			if err = announcer.register(client); err == nil {
+				// The registry may hand out a different TTL than before.
+				ticker.Reset(heartbeatInterval(announcer.ttl))
+			}
+		}
+		if err != nil {
+			log.Println("registry: heartbeat failed", err.Error())
+			var serverError rpc.ServerError
+			if !errors.As(err, &serverError) {
+				// The connection is broken or timed out, dial again on the next tick.
+				client.Close()
+				client = nil
+			}
+		}
+	}
+}
+
+// Stop deregisters the instance and stops the heartbeats. Every call to the registry has a timeout, so Stop returns
+// even when the registry does not answer.
+func (announcer *Announcer) Stop() {
+	close(announcer.stop)
+	<-announcer.done
+}
@@ -0,0 +1,157 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// The registry is itself a net/rpc service. Servers register their address under a service name and keep sending
+// heartbeats, clients look up the addresses of the live instances of a service. An instance which misses its
+// heartbeats for longer than its TTL is dropped.
+
+const ServiceName = "Registry"
+
+var ErrNotRegistered = errors.New("registry: instance is not registered")
+
+type RegisterRequest struct {
+	Service string
+	Address string
+	// How long the instance stays registered without a heartbeat, the registry's default is used when zero.
+	TTL time.Duration
+}
+
+type RegisterReply struct {
+	// The TTL the registry applied, instances should heartbeat well within it.
+	TTL time.Duration
+}
+
+type HeartbeatRequest struct {
+	Service string
+	Address string
+}
+
+type HeartbeatReply struct {
+	TTL time.Duration
+}
+
+type DeregisterRequest struct {
+	Service string
+	Address string
+}
+
+type DeregisterReply struct {
+	Removed bool
+}
+
+type LookupRequest struct {
+	Service string
+}
+
+type LookupReply struct {
+	Addresses []string
+}
+
+type instance struct {
+	ttl       time.Duration
+	expiresAt time.Time
+}
+
+type Registry struct {
+	mu         sync.Mutex
+	defaultTTL time.Duration
+	// Service name -> address -> instance
+	services map[string]map[string]*instance
+	now      func() time.Time
+}
+
+// New creates a registry which keeps instances registered for defaultTTL when they do not ask for a TTL of their own.
+func New(defaultTTL time.Duration) (*Registry, error) {
+	if defaultTTL <= 0 {
+		return nil, fmt.Errorf("registry: the default TTL must be positive, got %s", defaultTTL)
+	}
+	return &Registry{
+		defaultTTL: defaultTTL,
+		services:   make(map[string]map[string]*instance),
+		now:        time.Now,
+	}, nil
+}
+
+func (registry *Registry) Register(args *RegisterRequest, reply *RegisterReply) error {
+	if args.Service == "" || args.Address == "" {
+		return errors.New("registry: service and address are required")
+	}
+	ttl := args.TTL
+	if ttl <= 0 {
+		ttl = registry.defaultTTL
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	instances, ok := registry.services[args.Service]
+	if !ok {
+		instances = make(map[string]*instance)
+		registry.services[args.Service] = instances
+	}
+	instances[args.Address] = &instance{ttl: ttl, expiresAt: registry.now().Add(ttl)}
+	reply.TTL = ttl
+	return nil
+}
+
+// Heartbeat extends the registration. It fails with ErrNotRegistered once the instance has expired, after which
+// the instance has to register again.
+func (registry *Registry) Heartbeat(args *HeartbeatRequest, reply *HeartbeatReply) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.expire()
+
+	instance, ok := registry.services[args.Service][args.Address]
+	if !ok {
+		return ErrNotRegistered
+	}
+	instance.expiresAt = registry.now().Add(instance.ttl)
+	reply.TTL = instance.ttl
+	return nil
+}
+
+func (registry *Registry) Deregister(args *DeregisterRequest, reply *DeregisterReply) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	if _, ok := registry.services[args.Service][args.Address]; ok {
+		delete(registry.services[args.Service], args.Address)
+		reply.Removed = true
+	}
+	return nil
+}
+
+func (registry *Registry) Lookup(args *LookupRequest, reply *LookupReply) error {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.expire()
+
+	addresses := make([]string, 0, len(registry.services[args.Service]))
+	for address := range registry.services[args.Service] {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	reply.Addresses = addresses
+	return nil
+}
+
+// expire drops the instances which missed their heartbeats, it must be called with the registry lock held.
+// Expiring lazily on every access means the registry does not need a go routine of its own.
+func (registry *Registry) expire() {
+	now := registry.now()
+	for service, instances := range registry.services {
+		for address, instance := range instances {
+			if now.After(instance.expiresAt) {
+				delete(instances, address)
+			}
+		}
+		if len(instances) == 0 {
+			delete(registry.services, service)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"net"
+	"rpc/interceptor"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func startRegistry(t *testing.T, defaultTTL time.Duration) string {
+	t.Helper()
+	registry, err := New(defaultTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := interceptor.NewServer(interceptor.Options{})
+	if err := server.RegisterName(ServiceName, registry); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go server.Accept(listener)
+	return listener.Addr().String()
+}
+
+func TestNewRejectsNonPositiveTTL(t *testing.T) {
+	for _, ttl := range []time.Duration{0, -time.Second} {
+		if _, err := New(ttl); err == nil {
+			t.Errorf("New(%s) did not fail", ttl)
+		}
+	}
+}
+
+func TestHeartbeatIntervalIsClamped(t *testing.T) {
+	for _, test := range []struct {
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{ttl: 30 * time.Second, want: 10 * time.Second},
+		{ttl: time.Nanosecond, want: minHeartbeatInterval},
+		{ttl: 0, want: minHeartbeatInterval},
+	} {
+		if got := heartbeatInterval(test.ttl); got != test.want {
+			t.Errorf("heartbeatInterval(%s) = %s, want %s", test.ttl, got, test.want)
+		}
+	}
+}
+
+func TestAnnounceLookupStop(t *testing.T) {
+	address := startRegistry(t, time.Minute)
+	announcer, err := Announce(address, "WordCountServer", "10.0.0.1:5001", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addresses, err := Lookup(address, "WordCountServer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(addresses, []string{"10.0.0.1:5001"}) {
+		t.Errorf("Lookup = %v", addresses)
+	}
+
+	announcer.Stop()
+	if _, err := Lookup(address, "WordCountServer"); err == nil {
+		t.Error("the instance is still registered after Stop")
+	}
+}
+
+func TestCallTimesOutOnUnresponsiveRegistry(t *testing.T) {
+	// Accepts connections, but never answers.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	client, err := dial(listener.Addr().String(), time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	start := time.Now()
+	err = call(client, ServiceName+".Lookup", &LookupRequest{Service: "WordCountServer"}, new(LookupReply), 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("call returned %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("call took %s to time out", elapsed)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"rpc/interceptor"
+	"rpc/registry"
+	"time"
+)
+
+func main() {
+	address := flag.String("address", "localhost:5000", "address the registry listens on")
+	defaultTTL := flag.Duration("ttl", 15*time.Second, "how long an instance stays registered without a heartbeat")
+	flag.Parse()
+	if *defaultTTL <= 0 {
+		fmt.Fprintln(os.Stderr, "-ttl must be positive")
+		os.Exit(2)
+	}
+
+	registryService, err := registry.New(*defaultTTL)
+	if err != nil {
+		fmt.Println(err.Error())
+		return
+	}
+	// A dedicated rpc server keeps the registry apart from any service registered on rpc.DefaultServer, and
+	// recovers a panic in any of its methods.
+	server := interceptor.NewServer(interceptor.Options{})
+	err = server.RegisterName(registry.ServiceName, registryService)
+	if err != nil {
+		fmt.Printf("Unable to register the registry service %s", err.Error())
+		return
+	}
+
+	listener, err := net.Listen("tcp", *address)
+	if err != nil {
+		fmt.Printf("Unable to spin up the registry %s", err.Error())
+		return
+	}
+	fmt.Println("Registry is up at", *address)
+	server.Accept(listener)
+	fmt.Println("Registry shutdown")
+}
@@ -1,53 +1,94 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"net"
-	"net/rpc"
-	shared_types "rpc/shared_types"
-	"strings"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"rpc/auth"
+	"rpc/cache"
+	"rpc/interceptor"
+	"rpc/registry"
+	"rpc/wordcount"
+	"syscall"
+	"time"
 )
 
-type WordCountServer struct {
-	address string
-}
-
-func (wordCountServer *WordCountServer) Compute(args *shared_types.WordCountRequest, reply *shared_types.WordCountReply) error {
-	content := args.Content
-	counts := make(map[string]int)
-
-	words := strings.Fields(content)
-	for _, word := range words {
-		counts[word]++
+func main() {
+	address := flag.String("address", "localhost:5001", "address the word count server listens on")
+	cacheSize := flag.Int("cache-size", 1024, "number of results kept in the cache, 0 disables caching")
+	cacheTTL := flag.Duration("cache-ttl", 10*time.Minute, "how long a cached result is served, 0 keeps it until evicted")
+	tlsCert := flag.String("tls-cert", "", "certificate file, enables TLS together with -tls-key")
+	tlsKey := flag.String("tls-key", "", "private key file of the certificate")
+	tlsClientCA := flag.String("tls-client-ca", "", "CA file used to verify client certificates, enables mutual TLS")
+	maxRequestBytes := flag.Int64("max-request-bytes", 16<<20, "largest accepted request in bytes, 0 disables the limit")
+	metricsAddress := flag.String("metrics-address", "", "address to serve Prometheus metrics at /metrics, disabled when empty")
+	registryAddress := flag.String("registry", "", "address of the service registry to announce this server to, disabled when empty")
+	advertiseAddress := flag.String("advertise-address", "", "address announced to the registry, defaults to -address")
+	registryTTL := flag.Duration("registry-ttl", 0, "how long the registry keeps this server without a heartbeat, 0 uses the registry's default")
+	flag.Parse()
+	if *registryTTL < 0 {
+		fmt.Fprintln(os.Stderr, "-registry-ttl must not be negative")
+		os.Exit(2)
 	}
 
-	reply.Counts = counts
-	return nil
-}
-
-func (wordCountServer *WordCountServer) Listen() error {
-	rpc.Register(wordCountServer)
-	listener, err := net.Listen("tcp", wordCountServer.address)
-
-	if err != nil {
-		return err
+	config := wordcount.Config{Address: *address}
+	if *cacheSize > 0 {
+		config.Cache = cache.New(*cacheSize, *cacheTTL)
 	}
-	go func() {
-		fmt.Println("Server is up at", wordCountServer.address)
-		rpc.Accept(listener)
-		fmt.Println("Server shutdown")
-	}()
-
-	return nil
-}
+	if *tlsCert != "" || *tlsKey != "" {
+		tlsConfig, err := auth.ServerTLSConfig(*tlsCert, *tlsKey, *tlsClientCA)
+		if err != nil {
+			fmt.Printf("Unable to load TLS configuration %s", err.Error())
+			return
+		}
+		config.TLSConfig = tlsConfig
+	}
+	// The secret is read from the environment, so it does not show up in the process list.
+	config.Secret = []byte(os.Getenv("WORDCOUNT_SECRET"))
 
-func main() {
-	wordCountServer := new(WordCountServer)
-	wordCountServer.address = "localhost:5001"
+	metrics := interceptor.NewMetrics(nil)
+	config.CodecOptions = interceptor.Options{
+		MaxRequestBytes: *maxRequestBytes,
+		Interceptors: []interceptor.Interceptor{
+			interceptor.Logging(slog.Default()),
+			metrics.Interceptor(),
+		},
+	}
+	if *metricsAddress != "" {
+		serveMux := http.NewServeMux()
+		serveMux.Handle("/metrics", metrics)
+		go func() {
+			err := http.ListenAndServe(*metricsAddress, serveMux)
+			fmt.Println("Metrics server shutdown", err.Error())
+		}()
+	}
 
+	wordCountServer := wordcount.NewServer(config)
 	err := wordCountServer.Listen()
 
 	if err != nil {
 		fmt.Printf("Unable to spin up the word count server %s", err.Error())
+		return
+	}
+	defer wordCountServer.Close()
+
+	if *registryAddress != "" {
+		if *advertiseAddress == "" {
+			*advertiseAddress = *address
+		}
+		announcer, err := registry.Announce(*registryAddress, "WordCountServer", *advertiseAddress, *registryTTL)
+		if err != nil {
+			fmt.Printf("Unable to register with the registry %s", err.Error())
+			return
+		}
+		defer announcer.Stop()
 	}
+
+	// Listen serves the connections in a separate go routine, so keep the main routine alive until interrupted.
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	<-interrupt
 }
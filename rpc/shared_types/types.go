@@ -1,9 +1,48 @@
 package types
 
+// TokenizeOptions controls how the content is split into words before counting.
+type TokenizeOptions struct {
+	// Count "Hello" and "hello" as the same word.
+	IgnoreCase bool
+	// Strip leading and trailing punctuation, so "day." is counted as "day".
+	TrimPunctuation bool
+}
+
+// See version.go for the rules to follow when adding fields.
 type WordCountRequest struct {
+	// Since V2, see EffectiveVersion.
+	Version int
 	Content string
+	// Since V2.
+	Options TokenizeOptions
+	// Skip the server side result cache for this request. Since V2.
+	NoCache bool
 }
 
 type WordCountReply struct {
-	Counts map[string]int
+	// Version of the server which computed the reply. Since V2, see EffectiveVersion.
+	Version int
+	Counts  map[string]int
+}
+
+// HealthCheckRequest is sent by clients to probe if a server is still able to serve requests.
+type HealthCheckRequest struct {
+	Service string
+}
+
+type HealthCheckReply struct {
+	Status string
+}
+
+const HealthStatusServing = "SERVING"
+
+type CacheStatsRequest struct {
+	// Reset the hit and miss counters after reading them.
+	Reset bool
+}
+
+type CacheStatsReply struct {
+	Hits    uint64
+	Misses  uint64
+	Entries int
 }
@@ -0,0 +1,200 @@
+package types
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden payloads of the current version in testdata")
+
+// The wire types as they were in V1, to check that V1 peers and current peers understand each other.
+type v1WordCountRequest struct {
+	Content string
+}
+
+type v1WordCountReply struct {
+	Counts map[string]int
+}
+
+var (
+	v1Request = v1WordCountRequest{Content: "the cat and the hat"}
+	v1Reply   = v1WordCountReply{Counts: map[string]int{"the": 2, "cat": 1}}
+
+	v2Request = WordCountRequest{
+		Version: V2,
+		Content: "The cat. the hat",
+		Options: TokenizeOptions{IgnoreCase: true, TrimPunctuation: true},
+		NoCache: true,
+	}
+	v2Reply = WordCountReply{Version: V2, Counts: map[string]int{"the": 2, "cat": 1}}
+)
+
+type codec struct {
+	extension string
+	encode    func(value any) ([]byte, error)
+	decode    func(data []byte, value any) error
+}
+
+var codecs = []codec{
+	{
+		extension: "gob",
+		encode: func(value any) ([]byte, error) {
+			var buffer bytes.Buffer
+			err := gob.NewEncoder(&buffer).Encode(value)
+			return buffer.Bytes(), err
+		},
+		decode: func(data []byte, value any) error {
+			return gob.NewDecoder(bytes.NewReader(data)).Decode(value)
+		},
+	},
+	{
+		extension: "json",
+		encode: func(value any) ([]byte, error) {
+			return json.Marshal(value)
+		},
+		decode: json.Unmarshal,
+	},
+}
+
+// golden returns the committed payload, after rewriting it when -update is set and value is given.
+func golden(t *testing.T, codec codec, name string, value any) []byte {
+	t.Helper()
+	path := filepath.Join("testdata", name+"."+codec.extension)
+	if *update && value != nil {
+		data, err := codec.encode(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestV1PayloadsDecodeWithCurrentTypes(t *testing.T) {
+	for _, codec := range codecs {
+		t.Run(codec.extension, func(t *testing.T) {
+			// The V1 payloads are frozen, -update never rewrites them.
+			var request WordCountRequest
+			if err := codec.decode(golden(t, codec, "v1_request", nil), &request); err != nil {
+				t.Fatal(err)
+			}
+			want := WordCountRequest{Content: v1Request.Content}
+			if !reflect.DeepEqual(request, want) {
+				t.Errorf("request = %+v, want %+v", request, want)
+			}
+			if version := request.EffectiveVersion(); version != V1 {
+				t.Errorf("request version = %d, want V1", version)
+			}
+
+			var reply WordCountReply
+			if err := codec.decode(golden(t, codec, "v1_reply", nil), &reply); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(reply.Counts, v1Reply.Counts) || reply.Supports(V2) {
+				t.Errorf("reply = %+v, want the V1 counts %v without V2 support", reply, v1Reply.Counts)
+			}
+		})
+	}
+}
+
+func TestCurrentPayloadsDecodeWithV1Types(t *testing.T) {
+	for _, codec := range codecs {
+		t.Run(codec.extension, func(t *testing.T) {
+			var request v1WordCountRequest
+			if err := codec.decode(golden(t, codec, "v2_request", v2Request), &request); err != nil {
+				t.Fatal(err)
+			}
+			if request.Content != v2Request.Content {
+				t.Errorf("V1 server read content %q, want %q", request.Content, v2Request.Content)
+			}
+
+			var reply v1WordCountReply
+			if err := codec.decode(golden(t, codec, "v2_reply", v2Reply), &reply); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(reply.Counts, v2Reply.Counts) {
+				t.Errorf("V1 client read counts %v, want %v", reply.Counts, v2Reply.Counts)
+			}
+		})
+	}
+}
+
+func TestCurrentPayloadsRoundTrip(t *testing.T) {
+	for _, codec := range codecs {
+		t.Run(codec.extension, func(t *testing.T) {
+			var request WordCountRequest
+			if err := codec.decode(golden(t, codec, "v2_request", nil), &request); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(request, v2Request) {
+				t.Errorf("request = %+v, want %+v", request, v2Request)
+			}
+
+			var reply WordCountReply
+			if err := codec.decode(golden(t, codec, "v2_reply", nil), &reply); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(reply, v2Reply) {
+				t.Errorf("reply = %+v, want %+v", reply, v2Reply)
+			}
+		})
+	}
+}
+
+// encoding/json matches keys case insensitively when decoding, so a renamed key still decodes in Go. Peers in other
+// languages do not, so the keys of the V1 fields are checked exactly.
+func TestJSONKeysOfV1FieldsAreUnchanged(t *testing.T) {
+	for _, check := range []struct {
+		value any
+		key   string
+	}{
+		{v2Request, "Content"},
+		{v2Reply, "Counts"},
+	} {
+		data, err := json.Marshal(check.value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys map[string]any
+		if err := json.Unmarshal(data, &keys); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := keys[check.key]; !ok {
+			t.Errorf("%s has no %q key", data, check.key)
+		}
+	}
+}
+
+// TestWriteV1Payloads records the frozen V1 payloads, it only runs with -update when the testdata was lost.
+func TestWriteV1Payloads(t *testing.T) {
+	if !*update {
+		t.Skip("only run with -update")
+	}
+	for _, codec := range codecs {
+		for name, value := range map[string]any{"v1_request": v1Request, "v1_reply": v1Reply} {
+			path := filepath.Join("testdata", name+"."+codec.extension)
+			if _, err := os.Stat(path); err == nil {
+				continue
+			}
+			data, err := codec.encode(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package types
+
+// Versioning of the wire types.
+//
+// Both gob and JSON match the struct fields by name, skip the fields they do not know, and leave the fields that
+// were not sent at their zero value. The types stay compatible in both directions as long as these rules are kept:
+// 1. Fields are only ever added, never renamed, removed or changed to another type.
+// 2. Every new field is optional: its zero value must mean "behave like the previous version". That's why the
+//    request has NoCache instead of a UseCache field which old clients would never set.
+// 3. Every change that adds fields bumps CurrentVersion and is listed below.
+// 4. The wire types have no json tags, so JSON uses the field names as keys just like gob. A tag on an existing field
+//    renames its key and breaks the peers which do not know the tag.
+//
+// types_test.go decodes payloads of every version, committed in testdata, with the current types and the other way
+// around. Run go test -update once after adding a version to record its payloads.
+//
+// Version history:
+// V1: WordCountRequest{Content}, WordCountReply{Counts}. Clients of this version do not send a Version at all.
+// V2: WordCountRequest gains Version, Options and NoCache. WordCountReply gains Version.
+
+const (
+	V1 = 1
+	V2 = 2
+
+	CurrentVersion = V2
+)
+
+// EffectiveVersion returns the version the request was built for, treating a missing version as V1.
+func (request *WordCountRequest) EffectiveVersion() int {
+	if request.Version == 0 {
+		return V1
+	}
+	return request.Version
+}
+
+// EffectiveVersion returns the version of the server that sent the reply, treating a missing version as V1.
+func (reply *WordCountReply) EffectiveVersion() int {
+	if reply.Version == 0 {
+		return V1
+	}
+	return reply.Version
+}
+
+// Supports reports if a server answering with this reply understood every field of the given request version.
+// A V1 server silently ignores the options of a newer client, this lets the client notice it.
+func (reply *WordCountReply) Supports(version int) bool {
+	return reply.EffectiveVersion() >= version
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	shared_types "rpc/shared_types"
+	"unicode"
+)
+
+func runCount(arguments []string) error {
+	flagSet := flag.NewFlagSet("count", flag.ExitOnError)
+	connection := addConnectionFlags(flagSet)
+	ignoreCase := flagSet.Bool("ignore-case", false, "count words case insensitively")
+	trimPunctuation := flagSet.Bool("trim-punctuation", false, "strip punctuation around words")
+	noCache := flagSet.Bool("no-cache", false, "bypass the server side result cache")
+	format := flagSet.String("format", "table", "output format: table, json or csv")
+	sortBy := flagSet.String("sort", "count", "sort the words by: count or word")
+	top := flagSet.Int("top", 0, "only print the N first words, 0 prints all of them")
+	flagSet.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: wc-client count [flags] [files...]")
+		flagSet.PrintDefaults()
+	}
+	flagSet.Parse(arguments)
+
+	writeOutput, ok := outputFormats[*format]
+	if !ok {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	less, ok := sortOrders[*sortBy]
+	if !ok {
+		return fmt.Errorf("unknown sort order %q", *sortBy)
+	}
+
+	files := flagSet.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+
+	client, err := connection.connect()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	// Every file is read and sent in chunks, so a large input is neither held in memory nor rejected by the
+	// server's request size limit.
+	options := shared_types.TokenizeOptions{IgnoreCase: *ignoreCase, TrimPunctuation: *trimPunctuation}
+	totals := make(map[string]int)
+	for _, file := range files {
+		warned := false
+		err := readInput(file, chunkSize, func(content string) error {
+			args := &shared_types.WordCountRequest{
+				Version: shared_types.CurrentVersion,
+				Content: content,
+				Options: options,
+				NoCache: *noCache,
+			}
+			reply := new(shared_types.WordCountReply)
+			if err := client.Compute(args, reply); err != nil {
+				return fmt.Errorf("word count of %s failed: %w", file, err)
+			}
+			if args.Options != (shared_types.TokenizeOptions{}) && !reply.Supports(shared_types.V2) && !warned {
+				fmt.Fprintf(os.Stderr, "Server does not support tokenize options, %s was counted without them\n", file)
+				warned = true
+			}
+			for word, count := range reply.Counts {
+				totals[word] += count
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	rows := sortedRows(totals, less)
+	if *top > 0 && *top < len(rows) {
+		rows = rows[:*top]
+	}
+	return writeOutput(os.Stdout, rows)
+}
+
+// chunkSize is the most content sent in a single request, well below the server's default request size limit.
+const chunkSize = 1 << 20
+
+// readInput reads the file, or stdin for "-", and passes it to send in chunks of at most size bytes.
+func readInput(file string, size int, send func(content string) error) error {
+	if file == "-" {
+		return readChunks(os.Stdin, size, send)
+	}
+	input, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	return readChunks(input, size, send)
+}
+
+// readChunks cuts every chunk at the last whitespace in it, so no word is split across two requests. Only a word
+// longer than size has to be split.
+func readChunks(reader io.Reader, size int, send func(content string) error) error {
+	buf := make([]byte, size)
+	pending := 0
+	for {
+		n, err := io.ReadFull(reader, buf[pending:])
+		pending += n
+		end := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !end {
+			return err
+		}
+
+		cut := pending
+		if !end {
+			if i := bytes.LastIndexFunc(buf[:pending], unicode.IsSpace); i > 0 {
+				cut = i
+			}
+		}
+		if cut > 0 {
+			if err := send(string(buf[:cut])); err != nil {
+				return err
+			}
+		}
+		if end {
+			return nil
+		}
+		pending = copy(buf, buf[cut:pending])
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadChunks(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		size  int
+		want  []string
+		// Only a word longer than a chunk may be split.
+		splitsWords bool
+	}{
+		{"empty", "", 8, nil, false},
+		{"fits in one chunk", "one two", 8, []string{"one two"}, false},
+		{"cut at the last space", "one two three", 8, []string{"one two", " three"}, false},
+		{"cut at a newline", "one\ntwo three", 8, []string{"one\ntwo", " three"}, false},
+		{"several cuts", "one two three four", 6, []string{"one", " two", " three", " four"}, false},
+		{"word longer than a chunk", "abcdefghij k", 4, []string{"abcd", "efgh", "ij", " k"}, true},
+		{"multibyte space", "ab　cdef", 8, []string{"ab", "　cdef"}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			err := readChunks(strings.NewReader(test.input), test.size, func(content string) error {
+				got = append(got, content)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("chunks = %q, want %q", got, test.want)
+			}
+			if test.splitsWords {
+				return
+			}
+			if words := strings.Fields(strings.Join(got, "")); !reflect.DeepEqual(words, strings.Fields(test.input)) {
+				t.Errorf("words = %q, want %q", words, strings.Fields(test.input))
+			}
+		})
+	}
+}
+
+func TestReadChunksStopsOnSendError(t *testing.T) {
+	failed := errors.New("failed")
+	calls := 0
+	err := readChunks(strings.NewReader("one two three four"), 4, func(string) error {
+		calls++
+		return failed
+	})
+	if !errors.Is(err, failed) || calls != 1 {
+		t.Errorf("err = %v after %d calls, want %v after 1 call", err, calls, failed)
+	}
+}
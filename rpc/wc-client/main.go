@@ -0,0 +1,116 @@
+package main
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"net/rpc"
+	"os"
+	"rpc/auth"
+	"rpc/balancer"
+	"rpc/registry"
+	"strings"
+	"time"
+)
+
+// Usage: wc-client <command> [flags] [args...]
+// Commands:
+//   count [files...]  Counts the words of the given files, or of stdin when no file (or "-") is given.
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: wc-client <command> [flags] [args...]")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	fmt.Fprintln(os.Stderr, "  count [files...]  count the words of the given files, or of stdin")
+	fmt.Fprintln(os.Stderr, "Run 'wc-client <command> -h' to see the flags of a command.")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "count":
+		err = runCount(os.Args[2:])
+	case "-h", "-help", "--help", "help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
+
+// connectionFlags are shared by every command that talks to the word count servers.
+type connectionFlags struct {
+	addresses        *string
+	registryAddress  *string
+	leastOutstanding *bool
+	tlsCA            *string
+	tlsCert          *string
+	tlsKey           *string
+	tlsServerName    *string
+}
+
+func addConnectionFlags(flagSet *flag.FlagSet) *connectionFlags {
+	return &connectionFlags{
+		addresses:        flagSet.String("servers", "localhost:5001", "comma separated list of word count server addresses"),
+		registryAddress:  flagSet.String("registry", "", "look up the servers in the service registry at this address instead of using -servers"),
+		leastOutstanding: flagSet.Bool("least-outstanding", false, "send calls to the server with the fewest in-flight calls instead of round robin"),
+		tlsCA:            flagSet.String("tls-ca", "", "CA file used to verify the servers, enables TLS"),
+		tlsCert:          flagSet.String("tls-cert", "", "client certificate file for mutual TLS"),
+		tlsKey:           flagSet.String("tls-key", "", "private key file of the client certificate"),
+		tlsServerName:    flagSet.String("tls-server-name", "", "expected server name in the server certificate"),
+	}
+}
+
+// connect creates the balancer, the shared secret is read from the WORDCOUNT_SECRET environment variable.
+func (connectionFlags *connectionFlags) connect() (*balancer.Balancer, error) {
+	var tlsConfig *tls.Config
+	if *connectionFlags.tlsCA != "" || *connectionFlags.tlsCert != "" {
+		var err error
+		tlsConfig, err = auth.ClientTLSConfig(*connectionFlags.tlsCA, *connectionFlags.tlsCert, *connectionFlags.tlsKey, *connectionFlags.tlsServerName)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load TLS configuration: %w", err)
+		}
+	}
+	secret := []byte(os.Getenv("WORDCOUNT_SECRET"))
+
+	strategy := balancer.RoundRobin
+	if *connectionFlags.leastOutstanding {
+		strategy = balancer.LeastOutstanding
+	}
+
+	addresses := strings.Split(*connectionFlags.addresses, ",")
+	if *connectionFlags.registryAddress != "" {
+		var err error
+		addresses, err = registry.Lookup(*connectionFlags.registryAddress, "WordCountServer")
+		if err != nil {
+			return nil, fmt.Errorf("unable to look up the servers: %w", err)
+		}
+	}
+
+	client, err := balancer.New(balancer.Config{
+		Addresses: addresses,
+		Strategy:  strategy,
+		Dial: func(address string, timeout time.Duration) (*rpc.Client, error) {
+			conn, err := auth.Dial(address, timeout, tlsConfig, secret)
+			if err != nil {
+				return nil, err
+			}
+			return rpc.NewClient(conn), nil
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to spin up a client: %w", err)
+	}
+	return client, nil
+}
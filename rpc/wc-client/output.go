@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+)
+
+type row struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// Ties are always broken by the word, so the output is the same on every run.
+var sortOrders = map[string]func(a, b row) bool{
+	"count": func(a, b row) bool {
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Word < b.Word
+	},
+	"word": func(a, b row) bool {
+		return a.Word < b.Word
+	},
+}
+
+func sortedRows(counts map[string]int, less func(a, b row) bool) []row {
+	rows := make([]row, 0, len(counts))
+	for word, count := range counts {
+		rows = append(rows, row{Word: word, Count: count})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return less(rows[i], rows[j])
+	})
+	return rows
+}
+
+var outputFormats = map[string]func(w io.Writer, rows []row) error{
+	"table": writeTable,
+	"json":  writeJSON,
+	"csv":   writeCSV,
+}
+
+func writeTable(w io.Writer, rows []row) error {
+	tabWriter := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tabWriter, "WORD\tCOUNT\t")
+	for _, row := range rows {
+		fmt.Fprintf(tabWriter, "%s\t%d\t\n", row.Word, row.Count)
+	}
+	return tabWriter.Flush()
+}
+
+func writeJSON(w io.Writer, rows []row) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(rows)
+}
+
+func writeCSV(w io.Writer, rows []row) error {
+	csvWriter := csv.NewWriter(w)
+	csvWriter.Write([]string{"word", "count"})
+	for _, row := range rows {
+		csvWriter.Write([]string{row.Word, strconv.Itoa(row.Count)})
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
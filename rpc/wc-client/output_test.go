@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+var counts = map[string]int{"b": 2, "a": 2, "c": 5, "d": 1}
+
+func TestSortOrders(t *testing.T) {
+	tests := []struct {
+		sortBy string
+		want   []row
+	}{
+		// Ties on the count are broken by the word.
+		{"count", []row{{"c", 5}, {"a", 2}, {"b", 2}, {"d", 1}}},
+		{"word", []row{{"a", 2}, {"b", 2}, {"c", 5}, {"d", 1}}},
+	}
+	for _, test := range tests {
+		t.Run(test.sortBy, func(t *testing.T) {
+			if got := sortedRows(counts, sortOrders[test.sortBy]); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("sortedRows = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	rows := []row{{"hello", 12}, {"a,b", 3}, {`"quoted"`, 1}}
+	tests := []struct {
+		format string
+		rows   []row
+		want   string
+	}{
+		{"table", rows, "      WORD  COUNT\n" +
+			"     hello     12\n" +
+			"       a,b      3\n" +
+			"  \"quoted\"      1\n"},
+		{"table", nil, "  WORD  COUNT\n"},
+		{"json", rows, `[
+  {
+    "word": "hello",
+    "count": 12
+  },
+  {
+    "word": "a,b",
+    "count": 3
+  },
+  {
+    "word": "\"quoted\"",
+    "count": 1
+  }
+]
+`},
+		{"json", []row{}, "[]\n"},
+		{"csv", rows, "word,count\nhello,12\n\"a,b\",3\n\"\"\"quoted\"\"\",1\n"},
+		{"csv", nil, "word,count\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := outputFormats[test.format](&buf, test.rows); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != test.want {
+				t.Errorf("%s output:\n%s\nwant:\n%s", test.format, got, test.want)
+			}
+		})
+	}
+}
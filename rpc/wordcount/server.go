@@ -0,0 +1,217 @@
+package wordcount
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"rpc/auth"
+	"rpc/cache"
+	"rpc/interceptor"
+	shared_types "rpc/shared_types"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+type Config struct {
+	// Address to listen on, use "127.0.0.1:0" to pick a free port and read it back with Address.
+	Address string
+	// Results of previous requests, nil disables caching.
+	Cache *cache.Cache
+	// When set the listener only accepts TLS connections.
+	TLSConfig *tls.Config
+	// When set every connection must complete the shared secret handshake before it is served.
+	Secret []byte
+	// Codec options of every connection, holding the request size limit and the interceptors.
+	CodecOptions interceptor.Options
+}
+
+// WordCountServer is registered on its own interceptor.Server instead of rpc.DefaultServer, so several servers can
+// run in the same process, and a panic in one of its methods fails only that call.
+type WordCountServer struct {
+	config    Config
+	rpcServer *interceptor.Server
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+func NewServer(config Config) *WordCountServer {
+	return &WordCountServer{config: config, conns: make(map[net.Conn]struct{})}
+}
+
+func (wordCountServer *WordCountServer) Compute(args *shared_types.WordCountRequest, reply *shared_types.WordCountReply) error {
+	reply.Version = shared_types.CurrentVersion
+	useCache := wordCountServer.config.Cache != nil && !args.NoCache
+	var key string
+	if useCache {
+		key = cache.Key(args)
+		if counts, ok := wordCountServer.config.Cache.Get(key); ok {
+			reply.Counts = counts
+			return nil
+		}
+	}
+
+	counts := countWords(args.Content, args.Options)
+	if useCache {
+		wordCountServer.config.Cache.Add(key, counts)
+	}
+
+	reply.Counts = counts
+	return nil
+}
+
+func countWords(content string, options shared_types.TokenizeOptions) map[string]int {
+	counts := make(map[string]int)
+
+	words := strings.Fields(content)
+	for _, word := range words {
+		if options.TrimPunctuation {
+			word = strings.TrimFunc(word, unicode.IsPunct)
+			if word == "" {
+				continue
+			}
+		}
+		if options.IgnoreCase {
+			word = strings.ToLower(word)
+		}
+		counts[word]++
+	}
+	return counts
+}
+
+func (wordCountServer *WordCountServer) CacheStats(args *shared_types.CacheStatsRequest, reply *shared_types.CacheStatsReply) error {
+	if wordCountServer.config.Cache == nil {
+		return nil
+	}
+	stats := wordCountServer.config.Cache.Stats()
+	reply.Hits = stats.Hits
+	reply.Misses = stats.Misses
+	reply.Entries = stats.Entries
+	if args.Reset {
+		wordCountServer.config.Cache.ResetStats()
+	}
+	return nil
+}
+
+// HealthCheck is used by the client side balancer to find out if this server can still serve requests.
+func (wordCountServer *WordCountServer) HealthCheck(args *shared_types.HealthCheckRequest, reply *shared_types.HealthCheckReply) error {
+	reply.Status = shared_types.HealthStatusServing
+	return nil
+}
+
+func (wordCountServer *WordCountServer) Listen() error {
+	rpcServer := interceptor.NewServer(wordCountServer.config.CodecOptions)
+	if err := rpcServer.Register(wordCountServer); err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", wordCountServer.config.Address)
+
+	if err != nil {
+		return err
+	}
+	if wordCountServer.config.TLSConfig != nil {
+		listener = tls.NewListener(listener, wordCountServer.config.TLSConfig)
+	}
+
+	wordCountServer.mu.Lock()
+	wordCountServer.rpcServer = rpcServer
+	wordCountServer.listener = listener
+	wordCountServer.mu.Unlock()
+
+	wordCountServer.wg.Add(1)
+	go func() {
+		defer wordCountServer.wg.Done()
+		fmt.Println("Server is up at", listener.Addr())
+		wordCountServer.accept(listener)
+		fmt.Println("Server shutdown")
+	}()
+
+	return nil
+}
+
+// Address returns the address the server is actually listening on, which differs from the configured one when
+// the port was 0.
+func (wordCountServer *WordCountServer) Address() string {
+	wordCountServer.mu.Lock()
+	defer wordCountServer.mu.Unlock()
+	if wordCountServer.listener == nil {
+		return wordCountServer.config.Address
+	}
+	return wordCountServer.listener.Addr().String()
+}
+
+// Close stops accepting connections, closes the open ones and waits for their go routines to exit.
+func (wordCountServer *WordCountServer) Close() error {
+	wordCountServer.mu.Lock()
+	if wordCountServer.closed {
+		wordCountServer.mu.Unlock()
+		return nil
+	}
+	wordCountServer.closed = true
+	var err error
+	if wordCountServer.listener != nil {
+		err = wordCountServer.listener.Close()
+	}
+	for conn := range wordCountServer.conns {
+		conn.Close()
+	}
+	wordCountServer.mu.Unlock()
+
+	wordCountServer.wg.Wait()
+	return err
+}
+
+// track remembers an open connection so Close can shut it down, it reports false once the server is closed.
+func (wordCountServer *WordCountServer) track(conn net.Conn) bool {
+	wordCountServer.mu.Lock()
+	defer wordCountServer.mu.Unlock()
+	if wordCountServer.closed {
+		return false
+	}
+	wordCountServer.conns[conn] = struct{}{}
+	wordCountServer.wg.Add(1)
+	return true
+}
+
+func (wordCountServer *WordCountServer) untrack(conn net.Conn) {
+	wordCountServer.mu.Lock()
+	delete(wordCountServer.conns, conn)
+	wordCountServer.mu.Unlock()
+	wordCountServer.wg.Done()
+}
+
+// accept works like rpc.Accept, except that every connection is authenticated in its own go routine first, so
+// a slow or malicious client can not block the others.
+func (wordCountServer *WordCountServer) accept(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				fmt.Println("Unable to accept connection", err.Error())
+			}
+			return
+		}
+		if !wordCountServer.track(conn) {
+			conn.Close()
+			return
+		}
+		go func(conn net.Conn) {
+			defer wordCountServer.untrack(conn)
+			if len(wordCountServer.config.Secret) > 0 {
+				err := auth.ServerHandshake(conn, wordCountServer.config.Secret, 5*time.Second)
+				if err != nil {
+					fmt.Println("Rejected connection from", conn.RemoteAddr(), err.Error())
+					conn.Close()
+					return
+				}
+			}
+			wordCountServer.rpcServer.ServeConn(conn)
+		}(conn)
+	}
+}
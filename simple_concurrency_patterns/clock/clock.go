@@ -0,0 +1,99 @@
+package clock
+
+import "time"
+
+// Code that sleeps, waits on timers or measures elapsed time takes a Clock instead of calling the time package
+// directly. Production code passes Real(), checks pass a Fake which only moves when told to, so a job that runs
+// every second can be checked for an hour of behaviour in an instant, and always with the same outcome.
+
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+type realClock struct{}
+
+// Real returns the clock backed by the time package.
+func Real() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (timer realTimer) C() <-chan time.Time {
+	return timer.timer.C
+}
+
+func (timer realTimer) Stop() bool {
+	return timer.timer.Stop()
+}
+
+func (timer realTimer) Reset(d time.Duration) bool {
+	return timer.timer.Reset(d)
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (ticker realTicker) C() <-chan time.Time {
+	return ticker.ticker.C
+}
+
+func (ticker realTicker) Stop() {
+	ticker.ticker.Stop()
+}
+
+func (ticker realTicker) Reset(d time.Duration) {
+	ticker.ticker.Reset(d)
+}
+
+// OrReal returns clock, or the real clock when clock is nil, for config structs where the clock is optional.
+func OrReal(clock Clock) Clock {
+	if clock == nil {
+		return Real()
+	}
+	return clock
+}
@@ -0,0 +1,190 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fake is a Clock whose time only moves when Advance is called. Every timer, ticker, After and Sleep registers a
+// waiter which fires once the fake time reaches its deadline. A timer whose deadline is not after the current time
+// fires right away instead, so After(0) and Sleep(0) return without an Advance, like the real ones. The channels
+// have a buffer of one and a tick nobody received in time is dropped.
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+type fakeWaiter struct {
+	deadline time.Time
+	// Zero for timers, the interval for tickers.
+	period time.Duration
+	ch     chan time.Time
+}
+
+func NewFake(start time.Time) *Fake {
+	fake := &Fake{now: start}
+	fake.cond = sync.NewCond(&fake.mu)
+	return fake
+}
+
+func (fake *Fake) Now() time.Time {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	return fake.now
+}
+
+func (fake *Fake) Since(t time.Time) time.Duration {
+	return fake.Now().Sub(t)
+}
+
+func (fake *Fake) Sleep(d time.Duration) {
+	<-fake.After(d)
+}
+
+func (fake *Fake) After(d time.Duration) <-chan time.Time {
+	return fake.NewTimer(d).C()
+}
+
+func (fake *Fake) NewTimer(d time.Duration) Timer {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	waiter := &fakeWaiter{deadline: fake.now.Add(d), ch: make(chan time.Time, 1)}
+	fake.schedule(waiter)
+	return &fakeTimer{fake: fake, waiter: waiter}
+}
+
+func (fake *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	waiter := &fakeWaiter{deadline: fake.now.Add(d), period: d, ch: make(chan time.Time, 1)}
+	fake.add(waiter)
+	return &fakeTicker{fake: fake, waiter: waiter}
+}
+
+// Advance moves the time forward by d, firing every waiter whose deadline is reached in deadline order, with the
+// clock set to each deadline as it fires. A ticker fires at most once per Advance, like a real ticker whose reader
+// fell behind.
+func (fake *Fake) Advance(d time.Duration) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	target := fake.now.Add(d)
+	fired := make(map[*fakeWaiter]bool)
+	for {
+		sort.SliceStable(fake.waiters, func(i, j int) bool {
+			return fake.waiters[i].deadline.Before(fake.waiters[j].deadline)
+		})
+		if len(fake.waiters) == 0 || fake.waiters[0].deadline.After(target) {
+			break
+		}
+		waiter := fake.waiters[0]
+		fake.now = waiter.deadline
+		if !fired[waiter] {
+			select {
+			case waiter.ch <- fake.now:
+			default:
+			}
+			fired[waiter] = true
+		}
+		if waiter.period > 0 {
+			waiter.deadline = waiter.deadline.Add(waiter.period)
+		} else {
+			fake.remove(waiter)
+		}
+	}
+	fake.now = target
+	fake.cond.Broadcast()
+}
+
+// BlockUntil waits until at least n timers, tickers or sleepers are pending. Checks use it to know a go routine
+// reached its wait before advancing the clock past it.
+func (fake *Fake) BlockUntil(n int) {
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	for len(fake.waiters) < n {
+		fake.cond.Wait()
+	}
+}
+
+// schedule registers a timer's waiter, or fires it right away if its deadline was already reached. Like add and
+// remove it must be called with the lock held.
+func (fake *Fake) schedule(waiter *fakeWaiter) {
+	if waiter.deadline.After(fake.now) {
+		fake.add(waiter)
+		return
+	}
+	select {
+	case waiter.ch <- fake.now:
+	default:
+	}
+}
+
+// add and remove must be called with the lock held.
+func (fake *Fake) add(waiter *fakeWaiter) {
+	fake.waiters = append(fake.waiters, waiter)
+	fake.cond.Broadcast()
+}
+
+func (fake *Fake) remove(waiter *fakeWaiter) bool {
+	for i, other := range fake.waiters {
+		if other == waiter {
+			fake.waiters = append(fake.waiters[:i], fake.waiters[i+1:]...)
+			fake.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	fake   *Fake
+	waiter *fakeWaiter
+}
+
+func (timer *fakeTimer) C() <-chan time.Time {
+	return timer.waiter.ch
+}
+
+func (timer *fakeTimer) Stop() bool {
+	timer.fake.mu.Lock()
+	defer timer.fake.mu.Unlock()
+	return timer.fake.remove(timer.waiter)
+}
+
+func (timer *fakeTimer) Reset(d time.Duration) bool {
+	timer.fake.mu.Lock()
+	defer timer.fake.mu.Unlock()
+	active := timer.fake.remove(timer.waiter)
+	timer.waiter.deadline = timer.fake.now.Add(d)
+	timer.fake.schedule(timer.waiter)
+	return active
+}
+
+type fakeTicker struct {
+	fake   *Fake
+	waiter *fakeWaiter
+}
+
+func (ticker *fakeTicker) C() <-chan time.Time {
+	return ticker.waiter.ch
+}
+
+func (ticker *fakeTicker) Stop() {
+	ticker.fake.mu.Lock()
+	defer ticker.fake.mu.Unlock()
+	ticker.fake.remove(ticker.waiter)
+}
+
+func (ticker *fakeTicker) Reset(d time.Duration) {
+	ticker.fake.mu.Lock()
+	defer ticker.fake.mu.Unlock()
+	ticker.fake.remove(ticker.waiter)
+	ticker.waiter.deadline = ticker.fake.now.Add(d)
+	ticker.waiter.period = d
+	ticker.fake.add(ticker.waiter)
+}
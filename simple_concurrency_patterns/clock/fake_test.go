@@ -0,0 +1,110 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Unix(0, 0)
+
+// received reports whether ch holds a value, without blocking.
+func received(ch <-chan time.Time) (time.Time, bool) {
+	select {
+	case t := <-ch:
+		return t, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+func TestExpiredTimersFireImmediately(t *testing.T) {
+	tests := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"zero", 0},
+		{"negative", -time.Second},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fake := NewFake(start)
+			if fired, ok := received(fake.After(test.d)); !ok || !fired.Equal(start) {
+				t.Errorf("After(%s) fired %t at %s, want it to fire at once at %s", test.d, ok, fired, start)
+			}
+
+			slept := make(chan struct{})
+			go func() {
+				fake.Sleep(test.d)
+				close(slept)
+			}()
+			select {
+			case <-slept:
+			case <-time.After(time.Second):
+				t.Fatalf("Sleep(%s) blocked without an Advance", test.d)
+			}
+
+			timer := fake.NewTimer(time.Minute)
+			if !timer.Reset(test.d) {
+				t.Error("Reset of a pending timer reported it as stopped")
+			}
+			if _, ok := received(timer.C()); !ok {
+				t.Errorf("Reset(%s) did not fire the timer at once", test.d)
+			}
+			if timer.Stop() {
+				t.Error("Stop of a fired timer reported it as pending")
+			}
+		})
+	}
+}
+
+func TestAdvanceFiresTimersAtTheirDeadline(t *testing.T) {
+	fake := NewFake(start)
+	first := fake.NewTimer(time.Second)
+	second := fake.NewTimer(3 * time.Second)
+	stopped := fake.NewTimer(2 * time.Second)
+	if !stopped.Stop() {
+		t.Fatal("Stop of a pending timer reported it as stopped")
+	}
+
+	fake.Advance(999 * time.Millisecond)
+	if _, ok := received(first.C()); ok {
+		t.Fatal("timer fired before its deadline")
+	}
+	fake.Advance(5 * time.Second)
+	for _, test := range []struct {
+		timer Timer
+		want  time.Time
+	}{
+		{first, start.Add(time.Second)},
+		{second, start.Add(3 * time.Second)},
+	} {
+		if fired, ok := received(test.timer.C()); !ok || !fired.Equal(test.want) {
+			t.Errorf("timer fired %t at %s, want %s", ok, fired, test.want)
+		}
+	}
+	if _, ok := received(stopped.C()); ok {
+		t.Error("stopped timer fired")
+	}
+	if now := fake.Now(); !now.Equal(start.Add(5999 * time.Millisecond)) {
+		t.Errorf("Now() = %s after advancing, want %s", now, start.Add(5999*time.Millisecond))
+	}
+}
+
+func TestTickerFiresOncePerAdvance(t *testing.T) {
+	fake := NewFake(start)
+	ticker := fake.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	fake.Advance(time.Second)
+	if fired, ok := received(ticker.C()); !ok || !fired.Equal(start.Add(time.Second)) {
+		t.Fatalf("ticker fired %t at %s, want %s", ok, fired, start.Add(time.Second))
+	}
+	// Like a real ticker whose reader fell behind, the ticks in between are dropped.
+	fake.Advance(5 * time.Second)
+	if _, ok := received(ticker.C()); !ok {
+		t.Fatal("ticker did not fire")
+	}
+	if _, ok := received(ticker.C()); ok {
+		t.Error("ticker fired more than once in a single Advance")
+	}
+}
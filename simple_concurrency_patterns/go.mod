@@ -0,0 +1,3 @@
+module simple_concurrency_patterns
+
+go 1.21.1
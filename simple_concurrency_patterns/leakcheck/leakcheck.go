@@ -0,0 +1,74 @@
+package leakcheck
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// A go routine which never exits is a leak: it holds on to its stack and everything it references forever. The
+// checker takes a snapshot of the running go routines before the code under check runs, and afterwards reports
+// every go routine which was not there before. Go routines often need a moment to exit after the code returned,
+// so Check retries until the timeout passes.
+
+type Checker struct {
+	before map[string]bool
+}
+
+func Snapshot() *Checker {
+	before := make(map[string]bool)
+	for id := range goroutines() {
+		before[id] = true
+	}
+	return &Checker{before: before}
+}
+
+// Check returns an error listing the stacks of the go routines started after the snapshot and still running
+// after timeout.
+func (checker *Checker) Check(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		var leaked []string
+		for id, stack := range goroutines() {
+			if !checker.before[id] {
+				leaked = append(leaked, stack)
+			}
+		}
+		if len(leaked) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("leakcheck: %d leaked go routines:\n\n%s", len(leaked), strings.Join(leaked, "\n\n"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// goroutines returns the stack of every running go routine, except the calling one, by its id.
+func goroutines() map[string]string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := make(map[string]string)
+	// The first stack is always the one of the calling go routine.
+	for index, stack := range strings.Split(string(buf), "\n\n") {
+		if index == 0 {
+			continue
+		}
+		// Every stack starts with a header like "goroutine 42 [chan receive]:".
+		fields := strings.Fields(stack)
+		if len(fields) < 2 || fields[0] != "goroutine" {
+			continue
+		}
+		stacks[fields[1]] = stack
+	}
+	return stacks
+}
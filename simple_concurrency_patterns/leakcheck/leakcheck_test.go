@@ -0,0 +1,48 @@
+package leakcheck
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckPassesOnceTheGoRoutinesExited(t *testing.T) {
+	leaks := Snapshot()
+	stop := make(chan struct{})
+	go func() {
+		<-stop
+	}()
+	// Check retries, so a go routine which exits while it waits is not a leak.
+	time.AfterFunc(20*time.Millisecond, func() { close(stop) })
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheckReportsLeakedGoRoutines(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	leaks := Snapshot()
+	go leak(stop)
+	err := leaks.Check(50 * time.Millisecond)
+	if err == nil {
+		t.Fatal("Check did not report the leaked go routine")
+	}
+	if !strings.Contains(err.Error(), "leakcheck.leak") {
+		t.Errorf("the report does not hold the stack of the leaked go routine:\n%s", err)
+	}
+}
+
+func TestCheckIgnoresGoRoutinesStartedBeforeTheSnapshot(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	go leak(stop)
+	leaks := Snapshot()
+	if err := leaks.Check(0); err != nil {
+		t.Error(err)
+	}
+}
+
+func leak(stop chan struct{}) {
+	<-stop
+}
@@ -0,0 +1,87 @@
+package ledger
+
+type transferRequest struct {
+	from, to, amount int
+	reply            chan error
+}
+
+type balanceRequest struct {
+	account int
+	reply   chan int
+}
+
+// Actor shares the balances by communicating instead of communicating by sharing them: only the actor's go routine
+// ever touches them, every other go routine sends it a request and waits for the reply.
+type Actor struct {
+	accounts  int
+	transfers chan transferRequest
+	balances  chan balanceRequest
+	snapshots chan chan []int
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func NewActor(balances []int) *Actor {
+	actor := &Actor{
+		accounts:  len(balances),
+		transfers: make(chan transferRequest),
+		balances:  make(chan balanceRequest),
+		snapshots: make(chan chan []int),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go actor.run(append([]int(nil), balances...))
+	return actor
+}
+
+func (actor *Actor) run(balances []int) {
+	defer close(actor.done)
+	for {
+		select {
+		case <-actor.stop:
+			return
+		case request := <-actor.transfers:
+			if balances[request.from] < request.amount {
+				request.reply <- ErrInsufficientFunds
+				continue
+			}
+			balances[request.from] -= request.amount
+			balances[request.to] += request.amount
+			request.reply <- nil
+		case request := <-actor.balances:
+			request.reply <- balances[request.account]
+		case reply := <-actor.snapshots:
+			reply <- append([]int(nil), balances...)
+		}
+	}
+}
+
+func (actor *Actor) Transfer(from, to, amount int) error {
+	if err := validate(actor.accounts, from, to, amount); err != nil {
+		return err
+	}
+	reply := make(chan error, 1)
+	actor.transfers <- transferRequest{from: from, to: to, amount: amount, reply: reply}
+	return <-reply
+}
+
+func (actor *Actor) Balance(account int) (int, error) {
+	if account < 0 || account >= actor.accounts {
+		return 0, ErrUnknownAccount
+	}
+	reply := make(chan int, 1)
+	actor.balances <- balanceRequest{account: account, reply: reply}
+	return <-reply, nil
+}
+
+func (actor *Actor) Snapshot() []int {
+	reply := make(chan []int, 1)
+	actor.snapshots <- reply
+	return <-reply
+}
+
+// Close stops the actor's go routine, the ledger must not be used afterwards.
+func (actor *Actor) Close() {
+	close(actor.stop)
+	<-actor.done
+}
@@ -0,0 +1,41 @@
+package ledger
+
+import "sync"
+
+type GlobalLock struct {
+	mu       sync.Mutex
+	balances []int
+}
+
+func NewGlobalLock(balances []int) *GlobalLock {
+	return &GlobalLock{balances: append([]int(nil), balances...)}
+}
+
+func (ledger *GlobalLock) Transfer(from, to, amount int) error {
+	if err := validate(len(ledger.balances), from, to, amount); err != nil {
+		return err
+	}
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	if ledger.balances[from] < amount {
+		return ErrInsufficientFunds
+	}
+	ledger.balances[from] -= amount
+	ledger.balances[to] += amount
+	return nil
+}
+
+func (ledger *GlobalLock) Balance(account int) (int, error) {
+	if account < 0 || account >= len(ledger.balances) {
+		return 0, ErrUnknownAccount
+	}
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	return ledger.balances[account], nil
+}
+
+func (ledger *GlobalLock) Snapshot() []int {
+	ledger.mu.Lock()
+	defer ledger.mu.Unlock()
+	return append([]int(nil), ledger.balances...)
+}
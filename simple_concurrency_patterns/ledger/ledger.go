@@ -0,0 +1,73 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"simple_concurrency_patterns/clock"
+	"time"
+)
+
+// The alice/bob example in main.go keeps the invariant alice+bob == total by doing every transfer under one global
+// mutex. A ledger generalizes it to N accounts, with three ways of keeping the total constant:
+// 1. GlobalLock: one mutex for the whole ledger, simple but every transfer waits for every other one.
+// 2. PerAccountLock: one mutex per account, transfers between different accounts run in parallel. A transfer locks
+//    both of its accounts, always the lower index first, so two opposite transfers can never wait on each other.
+// 3. Actor: a single go routine owns the balances and serves requests sent over a channel, no locks at all.
+
+var (
+	ErrInsufficientFunds = errors.New("ledger: insufficient funds")
+	ErrUnknownAccount    = errors.New("ledger: unknown account")
+	ErrSameAccount       = errors.New("ledger: can not transfer to the same account")
+	ErrInvalidAmount     = errors.New("ledger: amount must be positive")
+)
+
+type Ledger interface {
+	Transfer(from, to, amount int) error
+	Balance(account int) (int, error)
+	// Snapshot returns all the balances as of a single point in time, so their sum must always equal the total.
+	Snapshot() []int
+}
+
+func validate(accounts, from, to, amount int) error {
+	if from < 0 || from >= accounts || to < 0 || to >= accounts {
+		return ErrUnknownAccount
+	}
+	if from == to {
+		return ErrSameAccount
+	}
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+func sum(balances []int) int {
+	total := 0
+	for _, balance := range balances {
+		total += balance
+	}
+	return total
+}
+
+// Audit checks the invariant on a consistent snapshot every interval until ctx is cancelled, calling onViolation
+// whenever the sum of the balances differs from expectedTotal. It returns the number of audits and violations.
+func Audit(ctx context.Context, clock clock.Clock, ledger Ledger, expectedTotal int, interval time.Duration, onViolation func(error)) (audits, violations int) {
+	ticker := clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return audits, violations
+		case <-ticker.C():
+		}
+
+		audits++
+		if total := sum(ledger.Snapshot()); total != expectedTotal {
+			violations++
+			if onViolation != nil {
+				onViolation(fmt.Errorf("ledger: invariant broken, expected total %d, found %d", expectedTotal, total))
+			}
+		}
+	}
+}
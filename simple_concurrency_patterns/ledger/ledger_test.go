@@ -0,0 +1,194 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"simple_concurrency_patterns/clock"
+	"simple_concurrency_patterns/leakcheck"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type implementation struct {
+	name string
+	// new returns the ledger and a function releasing its resources.
+	new func(balances []int) (Ledger, func())
+}
+
+var implementations = []implementation{
+	{"global-lock", func(balances []int) (Ledger, func()) { return NewGlobalLock(balances), func() {} }},
+	{"per-account-lock", func(balances []int) (Ledger, func()) { return NewPerAccountLock(balances), func() {} }},
+	{"actor", func(balances []int) (Ledger, func()) {
+		actor := NewActor(balances)
+		return actor, actor.Close
+	}},
+}
+
+func TestTransfer(t *testing.T) {
+	tests := []struct {
+		name             string
+		from, to, amount int
+		want             error
+		balances         []int
+	}{
+		{"moves the amount", 0, 1, 30, nil, []int{70, 130, 100}},
+		{"whole balance", 2, 0, 100, nil, []int{200, 100, 0}},
+		{"insufficient funds", 0, 1, 101, ErrInsufficientFunds, []int{100, 100, 100}},
+		{"unknown source", -1, 1, 10, ErrUnknownAccount, []int{100, 100, 100}},
+		{"unknown destination", 0, 3, 10, ErrUnknownAccount, []int{100, 100, 100}},
+		{"same account", 1, 1, 10, ErrSameAccount, []int{100, 100, 100}},
+		{"zero amount", 0, 1, 0, ErrInvalidAmount, []int{100, 100, 100}},
+		{"negative amount", 0, 1, -10, ErrInvalidAmount, []int{100, 100, 100}},
+	}
+	for _, implementation := range implementations {
+		for _, test := range tests {
+			t.Run(implementation.name+"/"+test.name, func(t *testing.T) {
+				ledger, close := implementation.new([]int{100, 100, 100})
+				defer close()
+				if err := ledger.Transfer(test.from, test.to, test.amount); !errors.Is(err, test.want) {
+					t.Fatalf("Transfer(%d, %d, %d) = %v, want %v", test.from, test.to, test.amount, err, test.want)
+				}
+				if balances := ledger.Snapshot(); !slices.Equal(balances, test.balances) {
+					t.Errorf("balances = %v, want %v", balances, test.balances)
+				}
+			})
+		}
+	}
+}
+
+func TestBalance(t *testing.T) {
+	for _, implementation := range implementations {
+		t.Run(implementation.name, func(t *testing.T) {
+			ledger, close := implementation.new([]int{10, 20})
+			defer close()
+			for account, want := range []int{10, 20} {
+				if balance, err := ledger.Balance(account); err != nil || balance != want {
+					t.Errorf("Balance(%d) = %d, %v, want %d", account, balance, err, want)
+				}
+			}
+			for _, account := range []int{-1, 2} {
+				if _, err := ledger.Balance(account); !errors.Is(err, ErrUnknownAccount) {
+					t.Errorf("Balance(%d) = %v, want ErrUnknownAccount", account, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLedgerCopiesTheBalances(t *testing.T) {
+	for _, implementation := range implementations {
+		t.Run(implementation.name, func(t *testing.T) {
+			balances := []int{10, 20}
+			ledger, close := implementation.new(balances)
+			defer close()
+			balances[0] = 0
+			ledger.Snapshot()[1] = 0
+			if snapshot := ledger.Snapshot(); !slices.Equal(snapshot, []int{10, 20}) {
+				t.Errorf("balances = %v, want them unaffected by changes to the callers' slices", snapshot)
+			}
+		})
+	}
+}
+
+func TestActorCloseStopsItsGoRoutine(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	actor := NewActor([]int{10, 20})
+	actor.Transfer(0, 1, 5)
+	actor.Close()
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestInvariantUnderStress hammers every implementation with random transfers while the auditor checks the
+// invariant, run it with -race to also catch the data races. The duration is shortened with -short.
+func TestInvariantUnderStress(t *testing.T) {
+	const accounts, workers = 100, 8
+	duration := time.Second
+	if testing.Short() {
+		duration = 100 * time.Millisecond
+	}
+	balances := make([]int, accounts)
+	for i := range balances {
+		balances[i] = 10000
+	}
+
+	for _, implementation := range implementations {
+		t.Run(implementation.name, func(t *testing.T) {
+			ledger, close := implementation.new(balances)
+			defer close()
+			ctx, cancel := context.WithTimeout(context.Background(), duration)
+			defer cancel()
+
+			var transfers atomic.Int64
+			var wg sync.WaitGroup
+			for worker := 0; worker < workers; worker++ {
+				wg.Add(1)
+				go func(seed int64) {
+					defer wg.Done()
+					random := rand.New(rand.NewSource(seed))
+					for ctx.Err() == nil {
+						from := random.Intn(accounts)
+						to := random.Intn(accounts)
+						if from == to {
+							continue
+						}
+						if ledger.Transfer(from, to, 1+random.Intn(100)) == nil {
+							transfers.Add(1)
+						}
+					}
+				}(int64(worker))
+			}
+
+			audits, violations := Audit(ctx, clock.Real(), ledger, accounts*10000, time.Millisecond, func(err error) {
+				t.Error(err)
+			})
+			wg.Wait()
+			if audits == 0 || transfers.Load() == 0 {
+				t.Fatalf("%d audits of %d transfers, the stress test did not run", audits, transfers.Load())
+			}
+			if violations != 0 {
+				t.Fatalf("invariant broken in %d of %d audits", violations, audits)
+			}
+			if total := sum(ledger.Snapshot()); total != accounts*10000 {
+				t.Errorf("total = %d after the transfers, want %d", total, accounts*10000)
+			}
+			t.Logf("%.0f transfers/s, %d audits", float64(transfers.Load())/duration.Seconds(), audits)
+		})
+	}
+}
+
+// BenchmarkTransfer runs random transfers between 100 accounts from GOMAXPROCS go routines, compare the
+// implementations with -cpu 1,4,8 to see how each of them scales.
+func BenchmarkTransfer(b *testing.B) {
+	const accounts = 100
+	balances := make([]int, accounts)
+	for i := range balances {
+		// Enough that no transfer of 1 ever runs out of funds.
+		balances[i] = 1 << 30
+	}
+
+	for _, implementation := range implementations {
+		b.Run(implementation.name, func(b *testing.B) {
+			ledger, close := implementation.new(balances)
+			defer close()
+			var seed atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				random := rand.New(rand.NewSource(seed.Add(1)))
+				for pb.Next() {
+					from := random.Intn(accounts)
+					to := (from + 1 + random.Intn(accounts-1)) % accounts
+					if err := ledger.Transfer(from, to, 1); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package ledger
+
+import "sync"
+
+type account struct {
+	mu      sync.Mutex
+	balance int
+}
+
+type PerAccountLock struct {
+	accounts []account
+}
+
+func NewPerAccountLock(balances []int) *PerAccountLock {
+	ledger := &PerAccountLock{accounts: make([]account, len(balances))}
+	for i, balance := range balances {
+		ledger.accounts[i].balance = balance
+	}
+	return ledger
+}
+
+func (ledger *PerAccountLock) Transfer(from, to, amount int) error {
+	if err := validate(len(ledger.accounts), from, to, amount); err != nil {
+		return err
+	}
+	// Locking in index order: if one transfer goes 1 -> 2 and another 2 -> 1, both lock account 1 first, so neither
+	// can hold account 2 while waiting for account 1.
+	first, second := from, to
+	if first > second {
+		first, second = second, first
+	}
+	ledger.accounts[first].mu.Lock()
+	defer ledger.accounts[first].mu.Unlock()
+	ledger.accounts[second].mu.Lock()
+	defer ledger.accounts[second].mu.Unlock()
+
+	if ledger.accounts[from].balance < amount {
+		return ErrInsufficientFunds
+	}
+	ledger.accounts[from].balance -= amount
+	ledger.accounts[to].balance += amount
+	return nil
+}
+
+func (ledger *PerAccountLock) Balance(account int) (int, error) {
+	if account < 0 || account >= len(ledger.accounts) {
+		return 0, ErrUnknownAccount
+	}
+	ledger.accounts[account].mu.Lock()
+	defer ledger.accounts[account].mu.Unlock()
+	return ledger.accounts[account].balance, nil
+}
+
+// Snapshot holds every account lock at once (again in index order), reading the balances one lock at a time could
+// see the money of an in-flight transfer twice or not at all.
+func (ledger *PerAccountLock) Snapshot() []int {
+	for i := range ledger.accounts {
+		ledger.accounts[i].mu.Lock()
+	}
+	balances := make([]int, len(ledger.accounts))
+	for i := range ledger.accounts {
+		balances[i] = ledger.accounts[i].balance
+	}
+	for i := range ledger.accounts {
+		ledger.accounts[i].mu.Unlock()
+	}
+	return balances
+}
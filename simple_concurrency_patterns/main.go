@@ -1,8 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
+	"simple_concurrency_patterns/clock"
+	"simple_concurrency_patterns/patterns"
+	"simple_concurrency_patterns/pipeline"
+	"simple_concurrency_patterns/primitives"
+	"simple_concurrency_patterns/quorum"
+	"simple_concurrency_patterns/scheduler"
+	"simple_concurrency_patterns/workerpool"
 	"sync"
 	"time"
 )
@@ -36,10 +44,52 @@ func main() {
 	}
 	wg2.Wait()
 
+	// Bounded concurrency with a worker pool
+	// Spawning a go routine per item does not scale to a large number of items, a worker pool runs them on a fixed
+	// number of go routines and Wait replaces waiting on a WaitGroup (or sleeping).
+	pool := workerpool.New[int](context.Background(), 3, 10)
+	for i := 0; i < 5; i++ {
+		rpcNo := i
+		pool.Submit(context.Background(), func(ctx context.Context) (int, error) {
+			sendRPC(rpcNo)
+			return rpcNo, nil
+		})
+	}
+	sent, _ := pool.Wait()
+	fmt.Println("Sent RPC calls", sent)
+
+	// Guarding the RPC calls with concurrency primitives
+	// The semaphore bounds how many calls run at once, the rate limiter how many start per second, and the circuit
+	// breaker stops calling altogether once too many calls in a row failed. primitives.WrapCaller puts a breaker in
+	// front of a net/rpc client, or the rpc module's balancer, the same way.
+	inFlight := primitives.NewWeighted(2)
+	limiter := primitives.NewTokenBucket(10, 2)
+	breaker := primitives.NewBreaker(primitives.BreakerConfig{FailureThreshold: 3, OpenTimeout: 1 * time.Second})
+	var wg3 sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg3.Add(1)
+		go func(rpcNo int) {
+			defer wg3.Done()
+			ctx := context.Background()
+			if err := inFlight.Acquire(ctx, 1); err != nil {
+				return
+			}
+			defer inFlight.Release(1)
+			if err := limiter.Wait(ctx); err != nil {
+				return
+			}
+			breaker.Execute(func() error {
+				sendRPC(rpcNo)
+				return nil
+			})
+		}(i)
+	}
+	wg3.Wait()
+
 	// Firing a periodic function / Periodic pings
 	// time.Sleep(1 * time.Second)
 	// fmt.Println("Started")
-	// go periodic()
+	// go patterns.Periodic(clock.Real(), 1*time.Second, func() { fmt.Println("Ping") })
 	// time.Sleep(5 * time.Second) // Waiting for a while just to see what periodic function does.
 
 	// Firing a periodic function with kill signal
@@ -47,7 +97,7 @@ func main() {
 	time.Sleep(1 * time.Second)
 	done := false
 	fmt.Println("Started")
-	go periodicWithKill(&done, &mu1)
+	go patterns.PeriodicWithKill(clock.Real(), &done, &mu1, 1*time.Second, func() { fmt.Println("Ping") })
 	time.Sleep(5 * time.Second)
 	// We can use channels as well to signal the done or kill signal
 	mu1.Lock()
@@ -56,57 +106,36 @@ func main() {
 	fmt.Println("Cancelled")
 	time.Sleep(3 * time.Second)
 
+	// Firing a periodic function with a scheduler
+	// The job waits on a ticker and the context in the same select, so stopping it takes effect right away instead
+	// of after the current sleep.
+	pings := scheduler.New(context.Background())
+	stopPing, _ := pings.Schedule(scheduler.Job{
+		Name:     "ping",
+		Interval: 1 * time.Second,
+		Run: func(ctx context.Context) error {
+			fmt.Println("Ping")
+			return nil
+		},
+	})
+	time.Sleep(3500 * time.Millisecond)
+	stopPing()
+	fmt.Println("Cancelled")
+	pings.Stop()
+
 	// Mutexes
-	counter := 0
-	var mu2 sync.Mutex
-	for i := 0; i < 1000; i++ {
-		go func() {
-			defer mu2.Unlock()
-			mu2.Lock()
-			counter++
-		}()
-	}
-	time.Sleep(100 * time.Millisecond) // Waiting for some time to actually process counter variable 1000 times, before the main routine
-	// happens to acquire a lock.
-	// Obviosuly we can use wait groups for waiting on routines to exit.
-	mu2.Lock()
-	fmt.Println(counter)
-	mu2.Unlock()
+	// 1000 go routines increment a shared counter, each one under the lock. A WaitGroup waits for all of them, instead
+	// of sleeping for a while and hoping they are done before the main routine acquires the lock.
+	fmt.Println(patterns.CountConcurrently(1000))
 
 	// Use locks when accessing a shared variable across threads.
 	// Another use case of locks is to ensure invariance, where we ensure the even though multiple threads manipulate the shared data
 	// the invariant on that data must hold at any point in execution.
-	var alice int = 10000
-	var bob int = 10000
-	var total int = alice + bob
-
 	// Invariant: The total sum of alice and bob should always remain the same during the course of computation
-	var mu3 sync.Mutex
-	for i := 0; i < 1000; i++ {
-		go func() {
-			defer mu3.Unlock()
-			mu3.Lock()
-			alice += 1
-			bob -= 1
-		}()
-	}
-
-	for i := 0; i < 1000; i++ {
-		go func() {
-			defer mu3.Unlock()
-			mu3.Lock()
-			alice -= 1
-			bob += 1
-		}()
-	}
-	start := time.Now()
-	for time.Since(start) < 1*time.Second {
-		mu3.Lock()
-		if alice+bob != total {
-			fmt.Printf("Found deviation in our invariance execpted %d, found %d\n", total, alice+bob)
-		}
-		mu3.Unlock()
-	}
+	transfers := patterns.TransferConcurrently(10000, 10000, 1000, func(expected, found int) {
+		fmt.Printf("Found deviation in our invariance execpted %d, found %d\n", expected, found)
+	})
+	fmt.Printf("Checked the invariant %d times, alice has %d and bob has %d\n", transfers.Checks, transfers.Alice, transfers.Bob)
 
 	// Condition variables
 	// Counting vote V1.0
@@ -116,9 +145,10 @@ func main() {
 
 	for i := 0; i < 10; i++ {
 		go func() {
-			defer mu4.Unlock()
 			vote := getVote()
+			// Only defer the unlock once the lock is actually held.
 			mu4.Lock()
+			defer mu4.Unlock()
 			if vote {
 				votes++
 			}
@@ -127,6 +157,7 @@ func main() {
 	}
 
 	// Waiting for either the total attempts to finish or received sufficient number of votes
+	// This busy waits, the main routine keeps grabbing and releasing the lock until the condition holds.
 	mu4.Lock()
 	for votes < 5 && finished != 10 {
 		mu4.Unlock()
@@ -138,30 +169,46 @@ func main() {
 		fmt.Println("Unfortunately, you lost!")
 	}
 	mu4.Unlock()
-}
 
-func sendRPC(rpcNo int) {
-	fmt.Println("Sending RPC call no", rpcNo)
-}
-
-func periodic() {
-	for {
-		fmt.Println("Ping")
-		time.Sleep(1 * time.Second)
+	// Counting vote V2.0
+	// A condition variable lets the main routine sleep until a voter signals that the counts changed, instead of
+	// spinning on the lock. cond.Wait releases the lock while sleeping and re-acquires it before returning.
+	if patterns.CountVotes(10, 5, getVote).Won {
+		fmt.Println("Received 5+ votes, you won!!")
+	} else {
+		fmt.Println("Unfortunately, you lost!")
 	}
-}
 
-func periodicWithKill(done *bool, mu *sync.Mutex) {
-	for {
-		mu.Lock()
-		if *done {
-			defer mu.Unlock()
-			return
+	// Counting vote V3.0
+	// The quorum package returns as soon as the outcome is known (a majority, or no chance of one left), gives up
+	// after a timeout and cancels the voters which did not answer yet.
+	voters := make([]quorum.Voter, 10)
+	for i := range voters {
+		voters[i] = func(ctx context.Context) (bool, error) {
+			return getVote(), nil
 		}
-		mu.Unlock()
-		fmt.Println("Ping")
-		time.Sleep(1 * time.Second)
 	}
+	result, err := quorum.Collect(context.Background(), voters, quorum.Majority(len(voters)), 1*time.Second)
+	if err != nil {
+		fmt.Println("Election did not finish in time", err.Error())
+	} else {
+		fmt.Printf("Election %s with %d granted and %d refused votes\n", result.Outcome, result.Granted, result.Refused)
+	}
+
+	// Pipelines
+	// Stages connected by channels: the numbers are squared by 3 workers (fan-out), and their results are merged
+	// back in the input order (ordered fan-in). A failing stage would cancel every other stage of the group.
+	group := pipeline.New(context.Background())
+	numbers := pipeline.Source(group, 0, 1, 2, 3, 4, 5)
+	squares := pipeline.OrderedMap(group, numbers, 3, 0, func(ctx context.Context, number int) (int, error) {
+		return number * number, nil
+	})
+	squared, err := pipeline.Collect(group, squares)
+	fmt.Println("Squares", squared, err)
+}
+
+func sendRPC(rpcNo int) {
+	fmt.Println("Sending RPC call no", rpcNo)
 }
 
 func getVote() bool {
@@ -0,0 +1,27 @@
+package patterns
+
+import "sync"
+
+// CountConcurrently increments a shared counter from the given number of go routines, protecting it with a mutex.
+// A WaitGroup tells when every go routine is done, instead of sleeping for a while and hoping they are.
+func CountConcurrently(goroutines int) int {
+	counter := 0
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mu.Lock()
+			defer mu.Unlock()
+			counter++
+		}()
+	}
+	wg.Wait()
+
+	// No other go routine is running anymore, but taking the lock costs nothing and keeps the race detector happy
+	// even if the code above changes.
+	mu.Lock()
+	defer mu.Unlock()
+	return counter
+}
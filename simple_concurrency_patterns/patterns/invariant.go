@@ -0,0 +1,68 @@
+package patterns
+
+import "sync"
+
+type TransferResult struct {
+	Alice int
+	Bob   int
+	// Number of times the checker looked at the invariant, and how often it found it broken.
+	Checks     int
+	Deviations int
+}
+
+// TransferConcurrently moves 1 from bob to alice, and 1 from alice to bob, in transfers go routines each, while a
+// checker keeps verifying the invariant alice+bob == total. The checker runs until every transfer finished, rather
+// than for a fixed amount of time. onDeviation is called for every broken invariant and may be nil.
+func TransferConcurrently(alice, bob, transfers int, onDeviation func(expected, found int)) TransferResult {
+	total := alice + bob
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < transfers; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			mu.Lock()
+			defer mu.Unlock()
+			alice += 1
+			bob -= 1
+		}()
+		go func() {
+			defer wg.Done()
+			mu.Lock()
+			defer mu.Unlock()
+			alice -= 1
+			bob += 1
+		}()
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+
+	result := TransferResult{}
+	check := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Checks++
+		if alice+bob != total {
+			result.Deviations++
+			if onDeviation != nil {
+				onDeviation(total, alice+bob)
+			}
+		}
+	}
+	for {
+		select {
+		case <-finished:
+			// One last check once everything settled.
+			check()
+			result.Alice, result.Bob = alice, bob
+			return result
+		default:
+			check()
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package patterns
+
+import (
+	"math/rand"
+	"simple_concurrency_patterns/clock"
+	"simple_concurrency_patterns/leakcheck"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Every pattern runs many times over, a data race in any of them fails the tests under -race.
+const runs = 50
+
+func TestCountConcurrently(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	for run := 0; run < runs; run++ {
+		if counter := CountConcurrently(1000); counter != 1000 {
+			t.Fatalf("run %d: counter = %d, want 1000", run, counter)
+		}
+	}
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTransferKeepsTheInvariant(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	for run := 0; run < runs; run++ {
+		result := TransferConcurrently(10000, 10000, 1000, nil)
+		if result.Deviations != 0 {
+			t.Fatalf("run %d: invariant broken %d times in %d checks", run, result.Deviations, result.Checks)
+		}
+		if result.Alice != 10000 || result.Bob != 10000 {
+			t.Fatalf("run %d: alice = %d and bob = %d, want 10000 each", run, result.Alice, result.Bob)
+		}
+	}
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCountVotes(t *testing.T) {
+	tests := []struct {
+		name    string
+		getVote func() bool
+		check   func(result VoteResult) bool
+	}{
+		// Every voter grants its vote, so the election must be won as soon as 5 votes arrived.
+		{"unanimous", func() bool { return true }, func(result VoteResult) bool { return result.Won && result.Votes >= 5 }},
+		// Every voter refuses, so the election can only be decided once all of them answered.
+		{"refused", func() bool { return false }, func(result VoteResult) bool { return !result.Won && result.Finished == 10 }},
+		{"random", func() bool { return rand.Intn(2) == 1 }, func(result VoteResult) bool { return result.Won == (result.Votes >= 5) }},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			leaks := leakcheck.Snapshot()
+			for run := 0; run < runs; run++ {
+				if result := CountVotes(10, 5, test.getVote); !test.check(result) {
+					t.Fatalf("run %d: unexpected result %+v", run, result)
+				}
+			}
+			if err := leaks.Check(time.Second); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// PeriodicWithKill runs on a fake clock: every step advances the time by exactly the interval and waits for the
+// go routine to reach its next sleep, so nothing depends on how fast the machine is.
+func TestPeriodicWithKillStops(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	fake := clock.NewFake(time.Unix(0, 0))
+	var mu sync.Mutex
+	done := false
+	var pings atomic.Int32
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		PeriodicWithKill(fake, &done, &mu, time.Second, func() { pings.Add(1) })
+	}()
+
+	for expected := int32(1); expected <= 5; expected++ {
+		fake.BlockUntil(1)
+		if pings.Load() != expected {
+			t.Fatalf("pings = %d, want %d", pings.Load(), expected)
+		}
+		fake.Advance(time.Second)
+	}
+
+	fake.BlockUntil(1)
+	mu.Lock()
+	done = true
+	mu.Unlock()
+	// The kill flag is only noticed once the current sleep is over.
+	fake.Advance(time.Second)
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("the periodic function did not stop after being killed")
+	}
+	if pings.Load() != 6 {
+		t.Errorf("pings = %d before stopping, want 6", pings.Load())
+	}
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
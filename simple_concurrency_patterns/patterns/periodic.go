@@ -0,0 +1,30 @@
+package patterns
+
+import (
+	"simple_concurrency_patterns/clock"
+	"sync"
+	"time"
+)
+
+// Periodic calls ping every interval, forever.
+func Periodic(clock clock.Clock, interval time.Duration, ping func()) {
+	for {
+		ping()
+		clock.Sleep(interval)
+	}
+}
+
+// PeriodicWithKill calls ping every interval until done is set. The flag is only checked between the sleeps, so
+// it may take up to a full interval to notice it, see the scheduler package for a version which stops right away.
+func PeriodicWithKill(clock clock.Clock, done *bool, mu *sync.Mutex, interval time.Duration, ping func()) {
+	for {
+		mu.Lock()
+		if *done {
+			mu.Unlock()
+			return
+		}
+		mu.Unlock()
+		ping()
+		clock.Sleep(interval)
+	}
+}
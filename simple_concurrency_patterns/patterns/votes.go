@@ -0,0 +1,39 @@
+package patterns
+
+import "sync"
+
+type VoteResult struct {
+	Votes    int
+	Finished int
+	Won      bool
+}
+
+// CountVotes asks voters for their vote concurrently and waits on a condition variable until either needed votes
+// were granted or every voter answered. The voters which did not answer yet keep running in the background until
+// getVote returns, their votes are not counted.
+func CountVotes(voters, needed int, getVote func() bool) VoteResult {
+	votes := 0
+	finished := 0
+	var mu sync.Mutex
+	cond := sync.NewCond(&mu)
+
+	for i := 0; i < voters; i++ {
+		go func() {
+			vote := getVote()
+			mu.Lock()
+			defer mu.Unlock()
+			if vote {
+				votes++
+			}
+			finished++
+			cond.Broadcast()
+		}()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for votes < needed && finished != voters {
+		cond.Wait()
+	}
+	return VoteResult{Votes: votes, Finished: finished, Won: votes >= needed}
+}
@@ -0,0 +1,236 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+)
+
+// A pipeline is a series of stages connected by channels, every stage runs in its own go routines, receives
+// values from its inbound channel and sends the results on its outbound channel. The stages of a pipeline share a
+// Group: the first stage that fails cancels the group's context, which makes every other stage, upstream as well as
+// downstream, stop sending and close its channel. Channel buffers are the only place values pile up, so the
+// memory a pipeline uses is bounded by the buffer sizes passed to the stages.
+
+// Group tracks the go routines of a pipeline and remembers its first error.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	once   sync.Once
+	err    error
+}
+
+func New(ctx context.Context) *Group {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{ctx: ctx, cancel: cancel}
+}
+
+// Context is cancelled once a stage fails or the parent context is done.
+func (group *Group) Context() context.Context {
+	return group.ctx
+}
+
+// Go runs f in a go routine of the group, an error returned by f cancels the whole pipeline.
+func (group *Group) Go(f func() error) {
+	group.wg.Add(1)
+	go func() {
+		defer group.wg.Done()
+		if err := f(); err != nil {
+			group.fail(err)
+		}
+	}()
+}
+
+func (group *Group) fail(err error) {
+	group.once.Do(func() {
+		group.err = err
+		group.cancel()
+	})
+}
+
+// Wait waits for every stage to exit and returns the first error. A pipeline stopped by its parent context
+// returns the context's error.
+func (group *Group) Wait() error {
+	group.wg.Wait()
+	group.fail(group.ctx.Err())
+	group.cancel()
+	return group.err
+}
+
+// send delivers value unless the pipeline was cancelled first.
+func send[T any](ctx context.Context, out chan<- T, value T) bool {
+	select {
+	case out <- value:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Source emits the items in order and closes the channel.
+func Source[T any](group *Group, buffer int, items ...T) <-chan T {
+	out := make(chan T, buffer)
+	group.Go(func() error {
+		defer close(out)
+		for _, item := range items {
+			if !send(group.ctx, out, item) {
+				return nil
+			}
+		}
+		return nil
+	})
+	return out
+}
+
+// Generate emits values produced by next until it reports false or fails.
+func Generate[T any](group *Group, buffer int, next func(ctx context.Context) (T, bool, error)) <-chan T {
+	out := make(chan T, buffer)
+	group.Go(func() error {
+		defer close(out)
+		for {
+			value, ok, err := next(group.ctx)
+			if err != nil {
+				return err
+			}
+			if !ok || !send(group.ctx, out, value) {
+				return nil
+			}
+		}
+	})
+	return out
+}
+
+// Stage transforms a single value.
+type Stage[In, Out any] func(ctx context.Context, in In) (Out, error)
+
+// Map fans the values of in out to workers go routines running stage, and fans their results back into a single
+// channel. The results come out in the order they are finished, use OrderedMap to keep the input order.
+func Map[In, Out any](group *Group, in <-chan In, workers, buffer int, stage Stage[In, Out]) <-chan Out {
+	if workers <= 0 {
+		workers = 1
+	}
+	outs := make([]<-chan Out, workers)
+	for i := range outs {
+		out := make(chan Out)
+		outs[i] = out
+		group.Go(func() error {
+			defer close(out)
+			for value := range in {
+				result, err := stage(group.ctx, value)
+				if err != nil {
+					return err
+				}
+				if !send(group.ctx, out, result) {
+					return nil
+				}
+			}
+			return nil
+		})
+	}
+	return Merge(group, buffer, outs...)
+}
+
+type indexed[T any] struct {
+	index int
+	value T
+}
+
+// OrderedMap is like Map, but emits the results in the order of the input. A slow value holds back the results
+// finished after it, at most workers+buffer of them are kept in flight.
+func OrderedMap[In, Out any](group *Group, in <-chan In, workers, buffer int, stage Stage[In, Out]) <-chan Out {
+	if workers <= 0 {
+		workers = 1
+	}
+	// Every value takes a slot when it is picked up and gives it back once emitted, which bounds the reorder buffer.
+	slots := make(chan struct{}, workers+buffer)
+	numbered := make(chan indexed[In])
+	group.Go(func() error {
+		defer close(numbered)
+		index := 0
+		for value := range in {
+			if !send(group.ctx, slots, struct{}{}) || !send(group.ctx, numbered, indexed[In]{index: index, value: value}) {
+				return nil
+			}
+			index++
+		}
+		return nil
+	})
+
+	results := Map(group, numbered, workers, 0, func(ctx context.Context, in indexed[In]) (indexed[Out], error) {
+		out, err := stage(ctx, in.value)
+		return indexed[Out]{index: in.index, value: out}, err
+	})
+
+	out := make(chan Out, buffer)
+	group.Go(func() error {
+		defer close(out)
+		pending := make(map[int]Out)
+		next := 0
+		for result := range results {
+			pending[result.index] = result.value
+			for value, ok := pending[next]; ok; value, ok = pending[next] {
+				delete(pending, next)
+				if !send(group.ctx, out, value) {
+					return nil
+				}
+				<-slots
+				next++
+			}
+		}
+		return nil
+	})
+	return out
+}
+
+// FanOut distributes the values of in across n channels, each value goes to whichever channel is ready first.
+func FanOut[T any](group *Group, in <-chan T, n, buffer int) []<-chan T {
+	outs := make([]<-chan T, n)
+	for i := range outs {
+		out := make(chan T, buffer)
+		outs[i] = out
+		group.Go(func() error {
+			defer close(out)
+			for value := range in {
+				if !send(group.ctx, out, value) {
+					return nil
+				}
+			}
+			return nil
+		})
+	}
+	return outs
+}
+
+// Merge fans the values of every channel in ins into a single channel, in no particular order.
+func Merge[T any](group *Group, buffer int, ins ...<-chan T) <-chan T {
+	out := make(chan T, buffer)
+	var wg sync.WaitGroup
+	wg.Add(len(ins))
+	for _, in := range ins {
+		in := in
+		group.Go(func() error {
+			defer wg.Done()
+			for value := range in {
+				if !send(group.ctx, out, value) {
+					return nil
+				}
+			}
+			return nil
+		})
+	}
+	group.Go(func() error {
+		wg.Wait()
+		close(out)
+		return nil
+	})
+	return out
+}
+
+// Collect drains the channel into a slice and waits for the whole pipeline.
+func Collect[T any](group *Group, in <-chan T) ([]T, error) {
+	var values []T
+	for value := range in {
+		values = append(values, value)
+	}
+	return values, group.Wait()
+}
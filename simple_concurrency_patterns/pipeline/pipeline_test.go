@@ -0,0 +1,129 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"simple_concurrency_patterns/leakcheck"
+	"slices"
+	"testing"
+	"time"
+)
+
+func square(ctx context.Context, value int) (int, error) {
+	return value * value, nil
+}
+
+func numbers(n int) []int {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = i
+	}
+	return values
+}
+
+func squares(n int) []int {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = i * i
+	}
+	return values
+}
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		buffer  int
+	}{
+		{"single worker", 1, 0},
+		{"no workers falls back to one", 0, 0},
+		{"fan out", 8, 0},
+		{"buffered", 8, 16},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			leaks := leakcheck.Snapshot()
+			group := New(context.Background())
+			results, err := Collect(group, Map(group, Source(group, 0, numbers(100)...), test.workers, test.buffer, square))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Map emits the results in the order they are finished.
+			slices.Sort(results)
+			if !slices.Equal(results, squares(100)) {
+				t.Errorf("results = %v, want the squares of 0 to 99", results)
+			}
+			if err := leaks.Check(time.Second); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestOrderedMapKeepsTheInputOrder(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	group := New(context.Background())
+	results, err := Collect(group, OrderedMap(group, Source(group, 0, numbers(100)...), 8, 4, func(ctx context.Context, value int) (int, error) {
+		// Earlier values take longer, so the workers finish them out of order.
+		time.Sleep(time.Duration(100-value) * 10 * time.Microsecond)
+		return value * value, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(results, squares(100)) {
+		t.Errorf("results = %v, want the squares of 0 to 99 in order", results)
+	}
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFanOutAndMerge(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	group := New(context.Background())
+	outs := FanOut(group, Source(group, 0, numbers(100)...), 4, 2)
+	results, err := Collect(group, Merge(group, 0, outs...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(results)
+	if !slices.Equal(results, numbers(100)) {
+		t.Errorf("results = %v, want every value exactly once", results)
+	}
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFailingStageCancelsThePipeline(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	failure := errors.New("stage failed")
+	group := New(context.Background())
+	// The source never runs out, only the failure can stop the pipeline.
+	source := Generate(group, 0, func(ctx context.Context) (int, bool, error) { return 1, true, nil })
+	mapped := Map(group, source, 4, 0, func(ctx context.Context, value int) (int, error) { return value, nil })
+	failing := Map(group, mapped, 1, 0, func(ctx context.Context, value int) (int, error) { return 0, failure })
+	if _, err := Collect(group, failing); !errors.Is(err, failure) {
+		t.Fatalf("Collect = %v, want the stage's error", err)
+	}
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCancelledParentStopsThePipeline(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	ctx, cancel := context.WithCancel(context.Background())
+	group := New(ctx)
+	source := Generate(group, 0, func(ctx context.Context) (int, bool, error) { return 1, true, nil })
+	mapped := OrderedMap(group, source, 4, 4, square)
+	<-mapped
+	cancel()
+	if _, err := Collect(group, mapped); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Collect = %v, want context.Canceled", err)
+	}
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,189 @@
+package primitives
+
+import (
+	"errors"
+	"simple_concurrency_patterns/clock"
+	"sync"
+	"time"
+)
+
+// A circuit breaker stops calling a dependency which keeps failing, giving it time to recover instead of piling
+// more load on it:
+// Closed: calls go through. FailureThreshold consecutive failures open the breaker.
+// Open: calls fail right away with ErrOpen. After OpenTimeout the breaker turns half-open.
+// HalfOpen: up to HalfOpenMaxCalls trial calls go through. SuccessThreshold successes close the breaker again,
+// a single failure opens it for another OpenTimeout.
+
+type BreakerState int
+
+const (
+	Closed BreakerState = iota
+	Open
+	HalfOpen
+)
+
+func (state BreakerState) String() string {
+	switch state {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+var ErrOpen = errors.New("primitives: circuit breaker is open")
+
+type BreakerConfig struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	HalfOpenMaxCalls int
+	SuccessThreshold int
+	// Called after every state change, outside of the breaker's lock, so it may use the breaker. Changes made by
+	// concurrent calls may be reported out of order.
+	OnStateChange func(from, to BreakerState)
+	// Measures the open timeout, the real clock when nil.
+	Clock clock.Clock
+}
+
+type Breaker struct {
+	config BreakerConfig
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	successes int
+	// Trial calls running while half-open.
+	inFlight int
+	openedAt time.Time
+	// Bumped on every state change, so the outcome of a call started in an earlier state is ignored.
+	generation int
+	// State changes made under the lock, reported to OnStateChange by unlock.
+	changes []stateChange
+}
+
+type stateChange struct {
+	from, to BreakerState
+}
+
+func NewBreaker(config BreakerConfig) *Breaker {
+	config.Clock = clock.OrReal(config.Clock)
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 10 * time.Second
+	}
+	if config.HalfOpenMaxCalls <= 0 {
+		config.HalfOpenMaxCalls = 1
+	}
+	if config.SuccessThreshold <= 0 {
+		config.SuccessThreshold = 1
+	}
+	return &Breaker{config: config}
+}
+
+func (breaker *Breaker) State() BreakerState {
+	breaker.mu.Lock()
+	defer breaker.unlock()
+	breaker.checkTimeout()
+	return breaker.state
+}
+
+// Execute runs call unless the breaker is open, and records its outcome. A panic in call counts as a failure and
+// is passed on to the caller.
+func (breaker *Breaker) Execute(call func() error) error {
+	generation, err := breaker.before()
+	if err != nil {
+		return err
+	}
+	success := false
+	defer func() {
+		breaker.after(generation, success)
+	}()
+	err = call()
+	success = err == nil
+	return err
+}
+
+func (breaker *Breaker) before() (int, error) {
+	breaker.mu.Lock()
+	defer breaker.unlock()
+	breaker.checkTimeout()
+	switch breaker.state {
+	case Open:
+		return 0, ErrOpen
+	case HalfOpen:
+		if breaker.inFlight >= breaker.config.HalfOpenMaxCalls {
+			return 0, ErrOpen
+		}
+		breaker.inFlight++
+	}
+	return breaker.generation, nil
+}
+
+func (breaker *Breaker) after(generation int, success bool) {
+	breaker.mu.Lock()
+	defer breaker.unlock()
+	if generation != breaker.generation {
+		return
+	}
+	if breaker.state == HalfOpen {
+		breaker.inFlight--
+	}
+
+	switch breaker.state {
+	case Closed:
+		if success {
+			breaker.failures = 0
+			return
+		}
+		breaker.failures++
+		if breaker.failures >= breaker.config.FailureThreshold {
+			breaker.setState(Open)
+		}
+	case HalfOpen:
+		if !success {
+			breaker.setState(Open)
+			return
+		}
+		breaker.successes++
+		if breaker.successes >= breaker.config.SuccessThreshold {
+			breaker.setState(Closed)
+		}
+	}
+}
+
+// checkTimeout turns an open breaker half-open once its timeout passed, it must be called with the lock held.
+func (breaker *Breaker) checkTimeout() {
+	if breaker.state == Open && breaker.config.Clock.Since(breaker.openedAt) >= breaker.config.OpenTimeout {
+		breaker.setState(HalfOpen)
+	}
+}
+
+// setState must be called with the lock held.
+func (breaker *Breaker) setState(state BreakerState) {
+	from := breaker.state
+	breaker.state = state
+	breaker.failures = 0
+	breaker.successes = 0
+	breaker.inFlight = 0
+	breaker.generation++
+	if state == Open {
+		breaker.openedAt = breaker.config.Clock.Now()
+	}
+	if breaker.config.OnStateChange != nil && from != state {
+		breaker.changes = append(breaker.changes, stateChange{from: from, to: state})
+	}
+}
+
+// unlock releases the lock and then reports the state changes made while it was held.
+func (breaker *Breaker) unlock() {
+	changes := breaker.changes
+	breaker.changes = nil
+	breaker.mu.Unlock()
+	for _, change := range changes {
+		breaker.config.OnStateChange(change.from, change.to)
+	}
+}
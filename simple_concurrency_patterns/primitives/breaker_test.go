@@ -0,0 +1,174 @@
+package primitives
+
+import (
+	"errors"
+	"io"
+	"net/rpc"
+	"simple_concurrency_patterns/clock"
+	"testing"
+	"time"
+)
+
+var errFailure = errors.New("failure")
+
+func TestBreaker(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	var changes []string
+	breaker := NewBreaker(BreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      10 * time.Second,
+		SuccessThreshold: 2,
+		HalfOpenMaxCalls: 2,
+		Clock:            fake,
+		OnStateChange:    func(from, to BreakerState) { changes = append(changes, from.String()+" -> "+to.String()) },
+	})
+
+	// A nil call means the breaker is expected to reject it without running it.
+	steps := []struct {
+		name    string
+		advance time.Duration
+		call    error
+		reject  bool
+		want    BreakerState
+	}{
+		{name: "failure below the threshold", call: errFailure, want: Closed},
+		{name: "success resets the failures", want: Closed},
+		{name: "first failure", call: errFailure, want: Closed},
+		{name: "second failure opens", call: errFailure, want: Open},
+		{name: "open rejects", reject: true, want: Open},
+		{name: "still open before the timeout", advance: 9 * time.Second, reject: true, want: Open},
+		{name: "failed trial opens again", advance: time.Second, call: errFailure, want: Open},
+		{name: "first successful trial", advance: 10 * time.Second, want: HalfOpen},
+		{name: "second successful trial closes", want: Closed},
+	}
+	for _, step := range steps {
+		fake.Advance(step.advance)
+		ran := false
+		err := breaker.Execute(func() error {
+			ran = true
+			return step.call
+		})
+		if step.reject {
+			if ran || !errors.Is(err, ErrOpen) {
+				t.Fatalf("%s: Execute ran %t and returned %v, want it rejected with ErrOpen", step.name, ran, err)
+			}
+		} else if !ran || !errors.Is(err, step.call) {
+			t.Fatalf("%s: Execute ran %t and returned %v, want it to run and return %v", step.name, ran, err, step.call)
+		}
+		if state := breaker.State(); state != step.want {
+			t.Fatalf("%s: state = %s, want %s", step.name, state, step.want)
+		}
+	}
+
+	want := []string{"closed -> open", "open -> half-open", "half-open -> open", "open -> half-open", "half-open -> closed"}
+	if len(changes) != len(want) {
+		t.Fatalf("state changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Fatalf("state changes = %v, want %v", changes, want)
+		}
+	}
+}
+
+func TestBreakerLimitsTrialCalls(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	breaker := NewBreaker(BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Second, HalfOpenMaxCalls: 1, Clock: fake})
+	breaker.Execute(func() error { return errFailure })
+	fake.Advance(time.Second)
+
+	// While the only trial call allowed is running, every other call is rejected.
+	err := breaker.Execute(func() error {
+		if err := breaker.Execute(func() error { return nil }); !errors.Is(err, ErrOpen) {
+			t.Errorf("second trial call returned %v, want ErrOpen", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state := breaker.State(); state != Closed {
+		t.Errorf("state = %s after a successful trial, want closed", state)
+	}
+}
+
+func TestBreakerReportsStateChangesOutsideTheLock(t *testing.T) {
+	var states []BreakerState
+	var breaker *Breaker
+	breaker = NewBreaker(BreakerConfig{
+		FailureThreshold: 1,
+		Clock:            clock.NewFake(time.Unix(0, 0)),
+		// Would deadlock if the callback ran with the lock held.
+		OnStateChange: func(from, to BreakerState) { states = append(states, breaker.State()) },
+	})
+	breaker.Execute(func() error { return errFailure })
+	if len(states) != 1 || states[0] != Open {
+		t.Errorf("states seen by the callback = %v, want [open]", states)
+	}
+}
+
+func TestBreakerPanicCountsAsFailure(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	breaker := NewBreaker(BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Second, HalfOpenMaxCalls: 1, Clock: fake})
+	breaker.Execute(func() error { return errFailure })
+	fake.Advance(time.Second)
+
+	func() {
+		defer func() {
+			if recovered := recover(); recovered != "boom" {
+				t.Errorf("recovered %v, want the panic passed on", recovered)
+			}
+		}()
+		breaker.Execute(func() error { panic("boom") })
+	}()
+	if state := breaker.State(); state != Open {
+		t.Fatalf("state = %s after a panicking trial, want open", state)
+	}
+
+	// The trial slot of the panicking call was released, so the next trial can run.
+	fake.Advance(time.Second)
+	if err := breaker.Execute(func() error { return nil }); err != nil {
+		t.Fatalf("trial after the panic returned %v", err)
+	}
+	if state := breaker.State(); state != Closed {
+		t.Errorf("state = %s, want closed", state)
+	}
+}
+
+// fakeCaller returns the errors of net/rpc's Client.Call, one per call.
+type fakeCaller struct {
+	errs  []error
+	calls int
+}
+
+func (caller *fakeCaller) Call(serviceMethod string, args any, reply any) error {
+	err := caller.errs[caller.calls]
+	caller.calls++
+	return err
+}
+
+func TestWrapCaller(t *testing.T) {
+	caller := &fakeCaller{errs: []error{
+		rpc.ServerError("word count failed"),
+		rpc.ServerError("word count failed"),
+		nil,
+		rpc.ErrShutdown,
+		io.ErrUnexpectedEOF,
+	}}
+	breaker := NewBreaker(BreakerConfig{FailureThreshold: 2, Clock: clock.NewFake(time.Unix(0, 0))})
+	wrapped := WrapCaller(breaker, caller)
+
+	for i, want := range append(caller.errs, ErrOpen) {
+		err := wrapped.Call("WordCountServer.Compute", nil, nil)
+		if !errors.Is(err, want) {
+			t.Fatalf("call %d returned %v, want %v", i, err, want)
+		}
+	}
+	// The server errors were passed on without opening the breaker, the two transport errors opened it.
+	if caller.calls != 5 {
+		t.Errorf("%d calls reached the client, want 5", caller.calls)
+	}
+	if state := breaker.State(); state != Open {
+		t.Errorf("state = %s, want open", state)
+	}
+}
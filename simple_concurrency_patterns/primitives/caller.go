@@ -0,0 +1,39 @@
+package primitives
+
+import (
+	"errors"
+	"net/rpc"
+)
+
+// Caller has the call signature of net/rpc's Client.Call, which the rpc module's balancer implements as well.
+type Caller interface {
+	Call(serviceMethod string, args any, reply any) error
+}
+
+type breakerCaller struct {
+	breaker *Breaker
+	caller  Caller
+}
+
+// WrapCaller returns a Caller which sends every call through the breaker. An rpc.ServerError was returned by the
+// method on a server which is up and answering, so it is passed on without counting as a failure, while transport
+// errors such as rpc.ErrShutdown do count.
+func WrapCaller(breaker *Breaker, caller Caller) Caller {
+	return &breakerCaller{breaker: breaker, caller: caller}
+}
+
+func (breakerCaller *breakerCaller) Call(serviceMethod string, args any, reply any) error {
+	var serverError error
+	err := breakerCaller.breaker.Execute(func() error {
+		err := breakerCaller.caller.Call(serviceMethod, args, reply)
+		if errors.As(err, new(rpc.ServerError)) {
+			serverError = err
+			return nil
+		}
+		return err
+	})
+	if serverError != nil {
+		return serverError
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package primitives
+
+import (
+	"context"
+	"errors"
+	"simple_concurrency_patterns/clock"
+	"sync"
+	"time"
+)
+
+// TokenBucket allows rate events per second on average, with bursts of up to burst events. The bucket holds at
+// most burst tokens and refills continuously, every event takes one token.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+	clock clock.Clock
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	return NewTokenBucketWithClock(rate, burst, clock.Real())
+}
+
+func NewTokenBucketWithClock(rate float64, burst int, clock clock.Clock) *TokenBucket {
+	return &TokenBucket{rate: rate, burst: float64(burst), clock: clock, tokens: float64(burst), last: clock.Now()}
+}
+
+// refill must be called with the lock held.
+func (bucket *TokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(bucket.last).Seconds(); elapsed > 0 {
+		bucket.tokens += elapsed * bucket.rate
+		if bucket.tokens > bucket.burst {
+			bucket.tokens = bucket.burst
+		}
+	}
+	bucket.last = now
+}
+
+// Allow takes a token if one is available right now.
+func (bucket *TokenBucket) Allow() bool {
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	bucket.refill(bucket.clock.Now())
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return true
+	}
+	return false
+}
+
+// reserve takes a token, possibly going into debt, and returns how long the caller must wait before using it.
+func (bucket *TokenBucket) reserve() time.Duration {
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	bucket.refill(bucket.clock.Now())
+	bucket.tokens--
+	if bucket.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-bucket.tokens / bucket.rate * float64(time.Second))
+}
+
+func (bucket *TokenBucket) cancelReservation() {
+	bucket.mu.Lock()
+	defer bucket.mu.Unlock()
+	bucket.tokens++
+	if bucket.tokens > bucket.burst {
+		bucket.tokens = bucket.burst
+	}
+}
+
+// Wait blocks until a token is available or ctx is done.
+func (bucket *TokenBucket) Wait(ctx context.Context) error {
+	if bucket.rate <= 0 {
+		if bucket.Allow() {
+			return nil
+		}
+		return errors.New("primitives: rate limiter with zero rate is out of tokens")
+	}
+	delay := bucket.reserve()
+	if delay == 0 {
+		return nil
+	}
+	timer := bucket.clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		bucket.cancelReservation()
+		return ctx.Err()
+	}
+}
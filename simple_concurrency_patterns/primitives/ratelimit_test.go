@@ -0,0 +1,78 @@
+package primitives
+
+import (
+	"context"
+	"errors"
+	"simple_concurrency_patterns/clock"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketAllow(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	bucket := NewTokenBucketWithClock(1, 2, fake)
+	steps := []struct {
+		advance time.Duration
+		want    []bool
+	}{
+		{0, []bool{true, true, false}},
+		{time.Second, []bool{true, false}},
+		{500 * time.Millisecond, []bool{false}},
+		{500 * time.Millisecond, []bool{true}},
+		// The bucket never holds more than burst tokens, however long it was idle.
+		{time.Hour, []bool{true, true, false}},
+	}
+	for i, step := range steps {
+		fake.Advance(step.advance)
+		for j, want := range step.want {
+			if got := bucket.Allow(); got != want {
+				t.Fatalf("step %d: Allow() number %d = %t, want %t", i, j+1, got, want)
+			}
+		}
+	}
+}
+
+func TestTokenBucketWait(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	bucket := NewTokenBucketWithClock(1, 1, fake)
+	if !bucket.Allow() {
+		t.Fatal("expected a full bucket to allow an event")
+	}
+
+	waited := make(chan error)
+	go func() {
+		waited <- bucket.Wait(context.Background())
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	select {
+	case err := <-waited:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return once a token was available")
+	}
+}
+
+func TestTokenBucketWaitCancelled(t *testing.T) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	bucket := NewTokenBucketWithClock(1, 1, fake)
+	bucket.Allow()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waited := make(chan error)
+	go func() {
+		waited <- bucket.Wait(ctx)
+	}()
+	fake.BlockUntil(1)
+	cancel()
+	if err := <-waited; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait = %v, want context.Canceled", err)
+	}
+	// The cancelled reservation gives its token back.
+	fake.Advance(time.Second)
+	if !bucket.Allow() {
+		t.Error("the cancelled Wait still holds a token")
+	}
+}
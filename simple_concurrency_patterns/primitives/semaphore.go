@@ -0,0 +1,104 @@
+package primitives
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+)
+
+// Weighted is a semaphore guarding a pool of size units, each caller acquires as many units as its work needs.
+// Waiters are served in arrival order, so a large request is not starved by a stream of small ones.
+type Weighted struct {
+	size    int64
+	mu      sync.Mutex
+	current int64
+	waiters list.List
+}
+
+type waiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+var ErrTooLarge = errors.New("primitives: request exceeds the semaphore size")
+
+func NewWeighted(size int64) *Weighted {
+	return &Weighted{size: size}
+}
+
+// Acquire blocks until n units are available or ctx is done.
+func (semaphore *Weighted) Acquire(ctx context.Context, n int64) error {
+	if n > semaphore.size {
+		return ErrTooLarge
+	}
+	semaphore.mu.Lock()
+	if semaphore.size-semaphore.current >= n && semaphore.waiters.Len() == 0 {
+		semaphore.current += n
+		semaphore.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	element := semaphore.waiters.PushBack(waiter{n: n, ready: ready})
+	semaphore.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		semaphore.mu.Lock()
+		defer semaphore.mu.Unlock()
+		select {
+		case <-ready:
+			// The units were granted just as ctx was done, hand them back.
+			semaphore.current -= n
+			semaphore.notifyWaiters()
+		default:
+			front := semaphore.waiters.Front() == element
+			semaphore.waiters.Remove(element)
+			// The waiters behind the first one may fit now that it gave up.
+			if front {
+				semaphore.notifyWaiters()
+			}
+		}
+		return ctx.Err()
+	}
+}
+
+// TryAcquire takes n units without blocking and reports whether it succeeded.
+func (semaphore *Weighted) TryAcquire(n int64) bool {
+	semaphore.mu.Lock()
+	defer semaphore.mu.Unlock()
+	if semaphore.size-semaphore.current >= n && semaphore.waiters.Len() == 0 {
+		semaphore.current += n
+		return true
+	}
+	return false
+}
+
+func (semaphore *Weighted) Release(n int64) {
+	semaphore.mu.Lock()
+	defer semaphore.mu.Unlock()
+	semaphore.current -= n
+	if semaphore.current < 0 {
+		panic("primitives: released more units than held")
+	}
+	semaphore.notifyWaiters()
+}
+
+// notifyWaiters wakes up the waiters in order as long as they fit, it must be called with the lock held.
+func (semaphore *Weighted) notifyWaiters() {
+	for {
+		next := semaphore.waiters.Front()
+		if next == nil {
+			return
+		}
+		waiter := next.Value.(waiter)
+		if semaphore.size-semaphore.current < waiter.n {
+			return
+		}
+		semaphore.current += waiter.n
+		semaphore.waiters.Remove(next)
+		close(waiter.ready)
+	}
+}
@@ -0,0 +1,219 @@
+package primitives
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitForWaiters blocks until n callers are queued on the semaphore, so a test knows Acquire is really blocked
+// before it releases units or cancels the context.
+func waitForWaiters(t *testing.T, semaphore *Weighted, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		semaphore.mu.Lock()
+		queued := semaphore.waiters.Len()
+		semaphore.mu.Unlock()
+		if queued == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d callers waiting, want %d", queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTryAcquire(t *testing.T) {
+	tests := []struct {
+		name string
+		size int64
+		held int64
+		n    int64
+		want bool
+	}{
+		{"empty", 4, 0, 4, true},
+		{"fits in the rest", 4, 1, 3, true},
+		{"zero units", 4, 4, 0, true},
+		{"one unit too many", 4, 1, 4, false},
+		{"full", 4, 4, 1, false},
+		{"larger than the size", 4, 0, 5, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			semaphore := NewWeighted(test.size)
+			if !semaphore.TryAcquire(test.held) {
+				t.Fatalf("TryAcquire(%d) on an empty semaphore failed", test.held)
+			}
+			if got := semaphore.TryAcquire(test.n); got != test.want {
+				t.Errorf("TryAcquire(%d) with %d of %d held = %t, want %t", test.n, test.held, test.size, got, test.want)
+			}
+		})
+	}
+}
+
+func TestTryAcquireDoesNotJumpTheQueue(t *testing.T) {
+	semaphore := NewWeighted(4)
+	semaphore.TryAcquire(3)
+	acquired := make(chan error)
+	go func() {
+		acquired <- semaphore.Acquire(context.Background(), 2)
+	}()
+	waitForWaiters(t, semaphore, 1)
+
+	// One unit is free, but handing it out would let small requests starve the waiting one.
+	if semaphore.TryAcquire(1) {
+		t.Error("TryAcquire succeeded while a larger caller was waiting")
+	}
+	semaphore.Release(3)
+	if err := <-acquired; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	tests := []struct {
+		name string
+		size int64
+		held int64
+		n    int64
+		// Called once Acquire is blocked, nil when it is expected to return right away.
+		unblock func(semaphore *Weighted, cancel context.CancelFunc)
+		want    error
+	}{
+		{name: "fits", size: 4, held: 2, n: 2},
+		{name: "larger than the size", size: 4, n: 5, want: ErrTooLarge},
+		{
+			name: "units released", size: 4, held: 4, n: 3,
+			unblock: func(semaphore *Weighted, cancel context.CancelFunc) { semaphore.Release(3) },
+		},
+		{
+			name: "cancelled while blocked", size: 4, held: 4, n: 1,
+			unblock: func(semaphore *Weighted, cancel context.CancelFunc) { cancel() },
+			want:    context.Canceled,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			semaphore := NewWeighted(test.size)
+			semaphore.TryAcquire(test.held)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			acquired := make(chan error)
+			go func() {
+				acquired <- semaphore.Acquire(ctx, test.n)
+			}()
+			if test.unblock != nil {
+				waitForWaiters(t, semaphore, 1)
+				test.unblock(semaphore, cancel)
+			}
+			select {
+			case err := <-acquired:
+				if !errors.Is(err, test.want) {
+					t.Fatalf("Acquire(%d) = %v, want %v", test.n, err, test.want)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("Acquire(%d) did not return", test.n)
+			}
+			waitForWaiters(t, semaphore, 0)
+		})
+	}
+}
+
+func TestCancelledAcquireHoldsNoUnits(t *testing.T) {
+	semaphore := NewWeighted(4)
+	semaphore.TryAcquire(4)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := semaphore.Acquire(ctx, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire = %v, want context.DeadlineExceeded", err)
+	}
+	semaphore.Release(4)
+	if !semaphore.TryAcquire(4) {
+		t.Error("the cancelled Acquire still holds units")
+	}
+}
+
+func TestCancelledFrontWaiterUnblocksTheOthers(t *testing.T) {
+	semaphore := NewWeighted(4)
+	semaphore.TryAcquire(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	large := make(chan error)
+	go func() {
+		large <- semaphore.Acquire(ctx, 4)
+	}()
+	waitForWaiters(t, semaphore, 1)
+	small := make(chan error)
+	go func() {
+		small <- semaphore.Acquire(context.Background(), 2)
+	}()
+	waitForWaiters(t, semaphore, 2)
+
+	// The small request fits, but waits behind the large one until it gives up.
+	cancel()
+	if err := <-large; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Acquire(4) = %v, want context.Canceled", err)
+	}
+	select {
+	case err := <-small:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the waiter behind the cancelled one was not woken up")
+	}
+}
+
+func TestReleaseWakesWaitersInOrder(t *testing.T) {
+	semaphore := NewWeighted(3)
+	semaphore.TryAcquire(3)
+	// No two of the waiters fit at the same time, so each one only runs once the one before released its units.
+	sizes := []int64{2, 3, 2}
+	order := make(chan int, len(sizes))
+	for i, n := range sizes {
+		i, n := i, n
+		go func() {
+			semaphore.Acquire(context.Background(), n)
+			order <- i
+			semaphore.Release(n)
+		}()
+		waitForWaiters(t, semaphore, i+1)
+	}
+	semaphore.Release(3)
+	for want := range sizes {
+		select {
+		case got := <-order:
+			if got != want {
+				t.Fatalf("waiter %d acquired before waiter %d", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("waiter %d did not acquire", want)
+		}
+	}
+}
+
+func TestReleaseMoreThanHeldPanics(t *testing.T) {
+	tests := []struct {
+		name    string
+		held    int64
+		release int64
+	}{
+		{"nothing held", 0, 1},
+		{"more than held", 2, 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			semaphore := NewWeighted(4)
+			semaphore.TryAcquire(test.held)
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Release(%d) with %d held did not panic", test.release, test.held)
+				}
+			}()
+			semaphore.Release(test.release)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package quorum
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// patterns.CountVotes waits until enough votes were granted or every voter answered. Collect decides as soon as the
+// outcome is known: once enough voters granted their vote the election is won, and once so many voters refused
+// that the rest can not make up for it, the election is lost. The voters still outstanding
+// at that point have their context cancelled, so they can stop waiting for replies nobody needs anymore.
+
+type Outcome int
+
+const (
+	Won Outcome = iota
+	Lost
+	// The deadline passed (or the context was cancelled) before the outcome was known.
+	Undecided
+)
+
+func (outcome Outcome) String() string {
+	switch outcome {
+	case Won:
+		return "won"
+	case Lost:
+		return "lost"
+	default:
+		return "undecided"
+	}
+}
+
+// Voter asks a single peer for its vote. A voter returning an error counts as a refused vote.
+type Voter func(ctx context.Context) (granted bool, err error)
+
+type Result struct {
+	Outcome Outcome
+	Granted int
+	Refused int
+	// Voters which did not answer before the outcome was known.
+	Pending int
+	// Errors of the voters which answered with an error.
+	Errors []error
+}
+
+// Majority returns the number of votes needed out of n voters.
+func Majority(n int) int {
+	return n/2 + 1
+}
+
+// Collect runs every voter in its own go routine and returns once needed votes were granted, enough were refused
+// to make that impossible, or timeout passed. A zero timeout only waits on ctx.
+func Collect(ctx context.Context, voters []Voter, needed int, timeout time.Duration) (Result, error) {
+	if needed <= 0 || needed > len(voters) {
+		return Result{}, errors.New("quorum: needed votes must be between 1 and the number of voters")
+	}
+
+	// Cancelling on return tells the outstanding voters to give up.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, timeout)
+		defer cancelTimeout()
+	}
+
+	type vote struct {
+		granted bool
+		err     error
+	}
+	// Buffered for every voter, so the voters still finishing after Collect returned never block.
+	votes := make(chan vote, len(voters))
+	for _, voter := range voters {
+		go func(voter Voter) {
+			granted, err := voter(ctx)
+			votes <- vote{granted: granted && err == nil, err: err}
+		}(voter)
+	}
+
+	result := Result{Pending: len(voters)}
+	for {
+		if result.Granted >= needed {
+			result.Outcome = Won
+			return result, nil
+		}
+		if result.Granted+result.Pending < needed {
+			result.Outcome = Lost
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			result.Outcome = Undecided
+			return result, ctx.Err()
+		case vote := <-votes:
+			result.Pending--
+			if vote.granted {
+				result.Granted++
+			} else {
+				result.Refused++
+			}
+			if vote.err != nil {
+				result.Errors = append(result.Errors, vote.err)
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package quorum
+
+import (
+	"context"
+	"errors"
+	"simple_concurrency_patterns/leakcheck"
+	"testing"
+	"time"
+)
+
+var errUnreachable = errors.New("peer unreachable")
+
+func grant(ctx context.Context) (bool, error)  { return true, nil }
+func refuse(ctx context.Context) (bool, error) { return false, nil }
+func fail(ctx context.Context) (bool, error)   { return false, errUnreachable }
+
+// hang never answers on its own, it only returns once Collect cancels the outstanding voters.
+func hang(ctx context.Context) (bool, error) {
+	<-ctx.Done()
+	return false, ctx.Err()
+}
+
+func TestCollect(t *testing.T) {
+	tests := []struct {
+		name    string
+		voters  []Voter
+		needed  int
+		timeout time.Duration
+		want    Result
+		wantErr error
+	}{
+		{
+			name:   "won unanimously",
+			voters: []Voter{grant, grant, grant},
+			needed: 2,
+			want:   Result{Outcome: Won, Granted: 2, Pending: 1},
+		},
+		{
+			name:   "won without waiting for the hanging voters",
+			voters: []Voter{grant, hang, grant, hang, grant},
+			needed: 3,
+			want:   Result{Outcome: Won, Granted: 3, Pending: 2},
+		},
+		{
+			name:   "lost as soon as the majority is out of reach",
+			voters: []Voter{refuse, hang, refuse, hang, refuse},
+			needed: 3,
+			want:   Result{Outcome: Lost, Refused: 3, Pending: 2},
+		},
+		{
+			name:   "errors count as refused votes",
+			voters: []Voter{fail, hang, fail},
+			needed: 2,
+			want:   Result{Outcome: Lost, Refused: 2, Pending: 1, Errors: []error{errUnreachable, errUnreachable}},
+		},
+		{
+			name:    "undecided once the timeout passed",
+			voters:  []Voter{grant, hang, hang},
+			needed:  2,
+			timeout: 10 * time.Millisecond,
+			want:    Result{Outcome: Undecided, Granted: 1, Pending: 2},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			leaks := leakcheck.Snapshot()
+			result, err := Collect(context.Background(), test.voters, test.needed, test.timeout)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Collect returned %v, want %v", err, test.wantErr)
+			}
+			// The order the voters answer in is not fixed, so only the counts of every outcome are compared.
+			if result.Outcome != test.want.Outcome || result.Granted != test.want.Granted ||
+				result.Refused != test.want.Refused || result.Pending != test.want.Pending ||
+				len(result.Errors) != len(test.want.Errors) {
+				t.Errorf("result = %+v, want %+v", result, test.want)
+			}
+			// The hanging voters must have been cancelled once Collect returned.
+			if err := leaks.Check(time.Second); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestCollectRejectsInvalidQuorums(t *testing.T) {
+	voters := []Voter{grant, grant, grant}
+	for _, needed := range []int{-1, 0, 4} {
+		if _, err := Collect(context.Background(), voters, needed, 0); err == nil {
+			t.Errorf("Collect with %d of %d votes needed did not fail", needed, len(voters))
+		}
+	}
+}
+
+func TestCollectStopsWhenTheContextIsCancelled(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := Collect(ctx, []Voter{hang, hang, hang}, 2, 0)
+	if !errors.Is(err, context.Canceled) || result.Outcome != Undecided {
+		t.Errorf("Collect = %+v, %v, want an undecided result and context.Canceled", result, err)
+	}
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMajority(t *testing.T) {
+	tests := []struct {
+		voters int
+		want   int
+	}{
+		{1, 1},
+		{2, 2},
+		{3, 2},
+		{4, 3},
+		{5, 3},
+	}
+	for _, test := range tests {
+		if got := Majority(test.voters); got != test.want {
+			t.Errorf("Majority(%d) = %d, want %d", test.voters, got, test.want)
+		}
+	}
+}
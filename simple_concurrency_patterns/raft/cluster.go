@@ -0,0 +1,106 @@
+package raft
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Cluster runs N peers on a shared in-memory network and records every leader it ever had, so the election
+// safety property (at most one leader per term) can be checked at any time.
+type Cluster struct {
+	Network *Network
+	Peers   []*Peer
+
+	mu      sync.Mutex
+	leaders map[int][]int // term -> ids of the peers which became leader in that term
+}
+
+func NewCluster(n int, config Config, networkConfig NetworkConfig) (*Cluster, error) {
+	if n <= 0 {
+		return nil, errors.New("raft: a cluster needs at least one peer")
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	cluster := &Cluster{Network: NewNetwork(networkConfig), leaders: make(map[int][]int)}
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = i
+	}
+	for _, id := range ids {
+		cluster.Peers = append(cluster.Peers, newPeer(id, ids, cluster.Network, config, cluster.recordLeader))
+	}
+	return cluster, nil
+}
+
+func (cluster *Cluster) recordLeader(id, term int) {
+	cluster.mu.Lock()
+	defer cluster.mu.Unlock()
+	cluster.leaders[term] = append(cluster.leaders[term], id)
+}
+
+func (cluster *Cluster) Start() {
+	for _, peer := range cluster.Peers {
+		peer.Start()
+	}
+}
+
+func (cluster *Cluster) Stop() {
+	for _, peer := range cluster.Peers {
+		peer.Stop()
+	}
+}
+
+// CheckOneLeaderPerTerm returns an error describing every term which had more than one leader.
+func (cluster *Cluster) CheckOneLeaderPerTerm() error {
+	cluster.mu.Lock()
+	defer cluster.mu.Unlock()
+
+	terms := make([]int, 0, len(cluster.leaders))
+	for term := range cluster.leaders {
+		terms = append(terms, term)
+	}
+	sort.Ints(terms)
+
+	var errs []error
+	for _, term := range terms {
+		if leaders := cluster.leaders[term]; len(leaders) > 1 {
+			errs = append(errs, fmt.Errorf("term %d has %d leaders: %v", term, len(leaders), leaders))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WaitForLeader waits until exactly one of the given peers considers itself the leader of the highest term any of
+// them is in, and returns its id and term. With no ids every peer is considered.
+func (cluster *Cluster) WaitForLeader(timeout time.Duration, ids ...int) (int, int, error) {
+	if len(ids) == 0 {
+		for _, peer := range cluster.Peers {
+			ids = append(ids, peer.ID())
+		}
+	}
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		highestTerm := -1
+		var leaders []int
+		for _, id := range ids {
+			term, state := cluster.Peers[id].State()
+			if term > highestTerm {
+				highestTerm = term
+				leaders = leaders[:0]
+			}
+			if term == highestTerm && state == Leader {
+				leaders = append(leaders, id)
+			}
+		}
+		if len(leaders) == 1 {
+			return leaders[0], highestTerm, nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return -1, -1, fmt.Errorf("raft: no single leader among %v after %s", ids, timeout)
+}
@@ -0,0 +1,130 @@
+package raft
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Network is an in-memory stand-in for the RPC layer between the peers. Every message is delayed by a random
+// latency and may be dropped, and the peers can be split into partitions which can not reach each other. A message
+// that does not arrive looks to the caller like an RPC which failed after the latency passed.
+
+type NetworkConfig struct {
+	MinLatency time.Duration
+	MaxLatency time.Duration
+	// Probability in [0, 1] that a request or its reply is lost.
+	DropRate float64
+}
+
+type handler interface {
+	handleRequestVote(args RequestVoteArgs) RequestVoteReply
+	handleAppendEntries(args AppendEntriesArgs) AppendEntriesReply
+}
+
+type Network struct {
+	config NetworkConfig
+
+	mu       sync.Mutex
+	random   *rand.Rand
+	handlers map[int]handler
+	// Peers can only reach the peers in the same partition.
+	partition map[int]int
+}
+
+func NewNetwork(config NetworkConfig) *Network {
+	return &Network{
+		config:    config,
+		random:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		handlers:  make(map[int]handler),
+		partition: make(map[int]int),
+	}
+}
+
+func (network *Network) register(id int, handler handler) {
+	network.mu.Lock()
+	defer network.mu.Unlock()
+	network.handlers[id] = handler
+	network.partition[id] = 0
+}
+
+// Partition splits the peers into the given groups, peers left out of every group are isolated on their own.
+func (network *Network) Partition(groups ...[]int) {
+	network.mu.Lock()
+	defer network.mu.Unlock()
+	for id := range network.partition {
+		network.partition[id] = -1 - id
+	}
+	for index, group := range groups {
+		for _, id := range group {
+			network.partition[id] = index
+		}
+	}
+}
+
+// Heal puts every peer back into a single partition.
+func (network *Network) Heal() {
+	network.mu.Lock()
+	defer network.mu.Unlock()
+	for id := range network.partition {
+		network.partition[id] = 0
+	}
+}
+
+// deliver decides the fate of a single message: how long it travels and whether it arrives.
+func (network *Network) deliver(from, to int) (time.Duration, handler, bool) {
+	network.mu.Lock()
+	defer network.mu.Unlock()
+	latency := network.config.MinLatency
+	if spread := network.config.MaxLatency - network.config.MinLatency; spread > 0 {
+		latency += time.Duration(network.random.Int63n(int64(spread)))
+	}
+	handler, ok := network.handlers[to]
+	if !ok || network.partition[from] != network.partition[to] {
+		return latency, nil, false
+	}
+	if network.random.Float64() < network.config.DropRate {
+		return latency, nil, false
+	}
+	return latency, handler, true
+}
+
+func (network *Network) wait(ctx context.Context, latency time.Duration) bool {
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// call sends a request and its reply across the network, reporting false if either of them was lost.
+func call[Reply any](network *Network, ctx context.Context, from, to int, handle func(handler) Reply) (Reply, bool) {
+	var reply Reply
+	latency, handler, ok := network.deliver(from, to)
+	if !network.wait(ctx, latency) || !ok {
+		return reply, false
+	}
+	reply = handle(handler)
+
+	latency, _, ok = network.deliver(to, from)
+	if !network.wait(ctx, latency) || !ok {
+		return reply, false
+	}
+	return reply, true
+}
+
+func (network *Network) requestVote(ctx context.Context, from, to int, args RequestVoteArgs) (RequestVoteReply, bool) {
+	return call(network, ctx, from, to, func(handler handler) RequestVoteReply {
+		return handler.handleRequestVote(args)
+	})
+}
+
+func (network *Network) appendEntries(ctx context.Context, from, to int, args AppendEntriesArgs) (AppendEntriesReply, bool) {
+	return call(network, ctx, from, to, func(handler handler) AppendEntriesReply {
+		return handler.handleAppendEntries(args)
+	})
+}
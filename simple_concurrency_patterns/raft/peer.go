@@ -0,0 +1,308 @@
+package raft
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"simple_concurrency_patterns/quorum"
+	"sync"
+	"time"
+)
+
+// Leader election of Raft (section 5.2 of the paper), without the log:
+// 1. Every peer starts as a follower. A follower which does not hear from a leader within its election timeout
+//    becomes a candidate, increments its term, votes for itself and asks the other peers for their votes.
+// 2. A peer grants at most one vote per term. Seeing a higher term in any message makes a peer adopt that term
+//    and step down to follower.
+// 3. A candidate which collects the votes of a majority becomes the leader of its term and keeps sending
+//    heartbeats (empty AppendEntries) to stop the others from starting elections.
+// The election timeouts are randomized, so usually one peer times out first and wins before the others start.
+
+type State int
+
+const (
+	Follower State = iota
+	Candidate
+	Leader
+)
+
+func (state State) String() string {
+	switch state {
+	case Leader:
+		return "leader"
+	case Candidate:
+		return "candidate"
+	default:
+		return "follower"
+	}
+}
+
+type Config struct {
+	// The election timeout of a peer is picked at random from [ElectionTimeout, 2*ElectionTimeout).
+	ElectionTimeout time.Duration
+	// Must be well below ElectionTimeout, so followers hear from the leader before timing out.
+	HeartbeatInterval time.Duration
+}
+
+// The peers tick every fifth of the heartbeat interval, see run.
+const ticksPerHeartbeat = 5
+
+func (config Config) validate() error {
+	if config.ElectionTimeout <= 0 {
+		return fmt.Errorf("raft: the election timeout must be positive, got %s", config.ElectionTimeout)
+	}
+	if config.HeartbeatInterval < ticksPerHeartbeat {
+		return fmt.Errorf("raft: the heartbeat interval must be at least %dns, got %s", ticksPerHeartbeat, config.HeartbeatInterval)
+	}
+	if config.HeartbeatInterval >= config.ElectionTimeout {
+		return fmt.Errorf("raft: the heartbeat interval %s must be below the election timeout %s",
+			config.HeartbeatInterval, config.ElectionTimeout)
+	}
+	return nil
+}
+
+type RequestVoteArgs struct {
+	Term        int
+	CandidateID int
+}
+
+type RequestVoteReply struct {
+	Term        int
+	VoteGranted bool
+}
+
+type AppendEntriesArgs struct {
+	Term     int
+	LeaderID int
+}
+
+type AppendEntriesReply struct {
+	Term    int
+	Success bool
+}
+
+type Peer struct {
+	id      int
+	peers   []int
+	network *Network
+	config  Config
+	// Called whenever this peer becomes the leader of a term, used to check the election safety.
+	onLeader func(id, term int)
+
+	mu              sync.Mutex
+	random          *rand.Rand
+	state           State
+	currentTerm     int
+	votedFor        int // -1 when the peer did not vote in the current term.
+	lastHeard       time.Time
+	electionTimeout time.Duration
+	lastHeartbeat   time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newPeer(id int, peers []int, network *Network, config Config, onLeader func(id, term int)) *Peer {
+	peer := &Peer{
+		id:       id,
+		peers:    peers,
+		network:  network,
+		config:   config,
+		onLeader: onLeader,
+		random:   rand.New(rand.NewSource(time.Now().UnixNano() + int64(id))),
+		votedFor: -1,
+	}
+	network.register(id, peer)
+	return peer
+}
+
+func (peer *Peer) ID() int {
+	return peer.id
+}
+
+// State returns the current term of the peer and its role in it.
+func (peer *Peer) State() (int, State) {
+	peer.mu.Lock()
+	defer peer.mu.Unlock()
+	return peer.currentTerm, peer.state
+}
+
+func (peer *Peer) Start() {
+	peer.mu.Lock()
+	peer.ctx, peer.cancel = context.WithCancel(context.Background())
+	peer.resetElectionTimer()
+	peer.mu.Unlock()
+
+	peer.wg.Add(1)
+	go peer.run()
+}
+
+// Stop halts the peer and waits for all its go routines, including the in-flight RPCs, to exit.
+func (peer *Peer) Stop() {
+	peer.cancel()
+	peer.wg.Wait()
+}
+
+// resetElectionTimer must be called with the peer lock held.
+func (peer *Peer) resetElectionTimer() {
+	peer.lastHeard = time.Now()
+	peer.electionTimeout = peer.config.ElectionTimeout + time.Duration(peer.random.Int63n(int64(peer.config.ElectionTimeout)))
+}
+
+// stepDown adopts a higher term and turns the peer into a follower, it must be called with the peer lock held.
+func (peer *Peer) stepDown(term int) {
+	if term > peer.currentTerm {
+		peer.currentTerm = term
+		peer.votedFor = -1
+	}
+	peer.state = Follower
+}
+
+func (peer *Peer) run() {
+	defer peer.wg.Done()
+	// A short tick keeps the timers precise enough without a timer per event.
+	ticker := time.NewTicker(peer.config.HeartbeatInterval / ticksPerHeartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-peer.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		peer.mu.Lock()
+		switch {
+		case peer.state == Leader && time.Since(peer.lastHeartbeat) >= peer.config.HeartbeatInterval:
+			peer.lastHeartbeat = time.Now()
+			peer.sendHeartbeats(peer.currentTerm)
+		case peer.state != Leader && time.Since(peer.lastHeard) >= peer.electionTimeout:
+			peer.startElection()
+		}
+		peer.mu.Unlock()
+	}
+}
+
+// startElection must be called with the peer lock held, the votes are collected in the background.
+func (peer *Peer) startElection() {
+	peer.currentTerm++
+	peer.state = Candidate
+	peer.votedFor = peer.id
+	peer.resetElectionTimer()
+	term := peer.currentTerm
+	args := RequestVoteArgs{Term: term, CandidateID: peer.id}
+
+	voters := []quorum.Voter{func(ctx context.Context) (bool, error) {
+		return true, nil // The candidate's own vote.
+	}}
+	for _, other := range peer.peers {
+		if other == peer.id {
+			continue
+		}
+		other := other
+		voters = append(voters, func(ctx context.Context) (bool, error) {
+			reply, ok := peer.network.requestVote(ctx, peer.id, other, args)
+			if !ok {
+				return false, nil
+			}
+			if reply.Term > term {
+				peer.mu.Lock()
+				peer.stepDown(reply.Term)
+				peer.mu.Unlock()
+			}
+			return reply.VoteGranted, nil
+		})
+	}
+
+	// Collect returns without waiting for the voters which did not answer yet, so every voter is tracked on the
+	// peer's wait group as well, and Stop also waits for those. Collect always starts every voter, as a majority
+	// of the peers is always a valid number of votes.
+	peer.wg.Add(len(voters))
+	for i, voter := range voters {
+		voter := voter
+		voters[i] = func(ctx context.Context) (bool, error) {
+			defer peer.wg.Done()
+			return voter(ctx)
+		}
+	}
+
+	// The election is abandoned once the next one would start anyway.
+	timeout := peer.electionTimeout
+	peer.wg.Add(1)
+	go func() {
+		defer peer.wg.Done()
+		result, _ := quorum.Collect(peer.ctx, voters, quorum.Majority(len(voters)), timeout)
+		if result.Outcome != quorum.Won {
+			return
+		}
+
+		peer.mu.Lock()
+		defer peer.mu.Unlock()
+		// Another peer may have won in the meantime, or this peer moved on to a later term.
+		if peer.state != Candidate || peer.currentTerm != term {
+			return
+		}
+		peer.state = Leader
+		peer.lastHeartbeat = time.Now()
+		peer.sendHeartbeats(term)
+		if peer.onLeader != nil {
+			peer.onLeader(peer.id, term)
+		}
+	}()
+}
+
+// sendHeartbeats must be called with the peer lock held.
+func (peer *Peer) sendHeartbeats(term int) {
+	args := AppendEntriesArgs{Term: term, LeaderID: peer.id}
+	for _, other := range peer.peers {
+		if other == peer.id {
+			continue
+		}
+		peer.wg.Add(1)
+		go func(other int) {
+			defer peer.wg.Done()
+			ctx, cancel := context.WithTimeout(peer.ctx, peer.config.HeartbeatInterval*2)
+			defer cancel()
+			reply, ok := peer.network.appendEntries(ctx, peer.id, other, args)
+			if !ok {
+				return
+			}
+			peer.mu.Lock()
+			defer peer.mu.Unlock()
+			if reply.Term > peer.currentTerm {
+				peer.stepDown(reply.Term)
+				peer.resetElectionTimer()
+			}
+		}(other)
+	}
+}
+
+func (peer *Peer) handleRequestVote(args RequestVoteArgs) RequestVoteReply {
+	peer.mu.Lock()
+	defer peer.mu.Unlock()
+
+	if args.Term > peer.currentTerm {
+		peer.stepDown(args.Term)
+	}
+	reply := RequestVoteReply{Term: peer.currentTerm}
+	if args.Term == peer.currentTerm && (peer.votedFor == -1 || peer.votedFor == args.CandidateID) {
+		peer.votedFor = args.CandidateID
+		// Granting a vote counts as hearing from a would be leader.
+		peer.resetElectionTimer()
+		reply.VoteGranted = true
+	}
+	return reply
+}
+
+func (peer *Peer) handleAppendEntries(args AppendEntriesArgs) AppendEntriesReply {
+	peer.mu.Lock()
+	defer peer.mu.Unlock()
+
+	if args.Term < peer.currentTerm {
+		return AppendEntriesReply{Term: peer.currentTerm}
+	}
+	// A valid leader exists for this term, so a candidate of the same term gives up.
+	peer.stepDown(args.Term)
+	peer.resetElectionTimer()
+	return AppendEntriesReply{Term: peer.currentTerm, Success: true}
+}
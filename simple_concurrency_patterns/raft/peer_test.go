@@ -0,0 +1,86 @@
+package raft
+
+import (
+	"fmt"
+	"simple_concurrency_patterns/leakcheck"
+	"testing"
+	"time"
+)
+
+var testConfig = Config{
+	ElectionTimeout:   150 * time.Millisecond,
+	HeartbeatInterval: 30 * time.Millisecond,
+}
+
+// Runs a 5 peer cluster over a lossy in-memory network, partitions and heals it a few times and verifies that there
+// is a single leader after every change and never more than one leader per term.
+func TestElectionSafetyUnderPartitions(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	cluster, err := NewCluster(5, testConfig, NetworkConfig{
+		MinLatency: 1 * time.Millisecond,
+		MaxLatency: 10 * time.Millisecond,
+		DropRate:   0.05,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cluster.Start()
+
+	waitForLeader := func(name string, ids ...int) int {
+		t.Helper()
+		leader, term, err := cluster.WaitForLeader(3*time.Second, ids...)
+		if err != nil {
+			cluster.Stop()
+			t.Fatalf("%s: %s", name, err)
+		}
+		t.Logf("%s: peer %d leads term %d", name, leader, term)
+		return leader
+	}
+
+	leader := waitForLeader("initial election")
+	for round := 0; round < 3; round++ {
+		// Cut the leader and one follower off, the other three still form a majority.
+		follower := (leader + 1) % 5
+		var majority []int
+		for id := 0; id < 5; id++ {
+			if id != leader && id != follower {
+				majority = append(majority, id)
+			}
+		}
+		cluster.Network.Partition([]int{leader, follower}, majority)
+		waitForLeader(fmt.Sprintf("round %d, majority partition elects a new leader", round), majority...)
+
+		cluster.Network.Heal()
+		leader = waitForLeader(fmt.Sprintf("round %d, healed network agrees on one leader", round))
+	}
+
+	cluster.Stop()
+	if err := cluster.CheckOneLeaderPerTerm(); err != nil {
+		t.Error("election safety:", err)
+	}
+	// Stop waits for every go routine of the peers, including the vote requests still in flight.
+	if err := leaks.Check(0); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewClusterValidatesConfig(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		peers  int
+		config Config
+	}{
+		{name: "no peers", peers: 0, config: testConfig},
+		{name: "zero election timeout", peers: 3, config: Config{HeartbeatInterval: 30 * time.Millisecond}},
+		{name: "zero heartbeat interval", peers: 3, config: Config{ElectionTimeout: 150 * time.Millisecond}},
+		{name: "heartbeat interval below the tick", peers: 3, config: Config{ElectionTimeout: time.Second, HeartbeatInterval: 4}},
+		{name: "heartbeat interval above the election timeout", peers: 3,
+			config: Config{ElectionTimeout: 100 * time.Millisecond, HeartbeatInterval: 200 * time.Millisecond}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewCluster(test.peers, test.config, NetworkConfig{}); err == nil {
+				t.Error("NewCluster did not fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"simple_concurrency_patterns/clock"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// patterns.Periodic and patterns.PeriodicWithKill sleep between the pings and check a shared flag under a mutex, so a
+// kill signal is only noticed after the current sleep ends. Here every job waits on a time.Ticker (or time.Timer)
+// and on a context.Context in the same select, so stopping a job takes effect immediately and also cancels the
+// context passed to a run in progress.
+
+type Mode int
+
+const (
+	// FixedRate starts a run every Interval, regardless of how long the runs take.
+	FixedRate Mode = iota
+	// FixedDelay waits Interval after a run finished before starting the next one.
+	FixedDelay
+)
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Mode     Mode
+	// Every run is delayed by a random duration in [0, Jitter), so jobs started together do not fire together.
+	Jitter time.Duration
+	// By default a FixedRate tick is skipped while the previous run is still in progress.
+	AllowOverlap bool
+	Run          func(ctx context.Context) error
+	// Called with the error of every failed run, from the go routine that ran the job.
+	OnError func(name string, err error)
+}
+
+type Scheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	clock  clock.Clock
+	wg     sync.WaitGroup
+}
+
+// New creates a scheduler whose jobs all stop once ctx is cancelled or Stop is called.
+func New(ctx context.Context) *Scheduler {
+	return NewWithClock(ctx, clock.Real())
+}
+
+// NewWithClock creates a scheduler whose tickers and timers come from clock, pass a clock.Fake to drive the
+// jobs without waiting.
+func NewWithClock(ctx context.Context, clock clock.Clock) *Scheduler {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Scheduler{ctx: ctx, cancel: cancel, clock: clock}
+}
+
+// Schedule starts the job in the background and returns a function which stops just this job. The stop
+// function returns once the job's go routines exited.
+func (scheduler *Scheduler) Schedule(job Job) (stop func(), err error) {
+	if job.Interval <= 0 {
+		return nil, errors.New("scheduler: interval must be positive")
+	}
+	if job.Run == nil {
+		return nil, errors.New("scheduler: job has nothing to run")
+	}
+
+	ctx, cancel := context.WithCancel(scheduler.ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	scheduler.wg.Add(1)
+	go func() {
+		defer scheduler.wg.Done()
+		defer wg.Done()
+		if job.Mode == FixedDelay {
+			runFixedDelay(ctx, scheduler.clock, job)
+		} else {
+			runFixedRate(ctx, scheduler.clock, job)
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}, nil
+}
+
+// Stop stops every job and waits for their go routines to exit.
+func (scheduler *Scheduler) Stop() {
+	scheduler.cancel()
+	scheduler.wg.Wait()
+}
+
+// runFixedRate starts every run in a go routine of its own, and waits for the runs in progress before it returns.
+func runFixedRate(ctx context.Context, clock clock.Clock, job Job) {
+	ticker := clock.NewTicker(job.Interval)
+	defer ticker.Stop()
+	var runs sync.WaitGroup
+	defer runs.Wait()
+	var running atomic.Bool
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+		}
+
+		if !job.AllowOverlap && !running.CompareAndSwap(false, true) {
+			continue
+		}
+		runs.Add(1)
+		go func() {
+			defer runs.Done()
+			if !job.AllowOverlap {
+				defer running.Store(false)
+			}
+			if wait(ctx, clock, jitter(job.Jitter)) {
+				runOnce(ctx, job)
+			}
+		}()
+	}
+}
+
+func runFixedDelay(ctx context.Context, clock clock.Clock, job Job) {
+	for {
+		if !wait(ctx, clock, job.Interval+jitter(job.Jitter)) {
+			return
+		}
+		runOnce(ctx, job)
+	}
+}
+
+func runOnce(ctx context.Context, job Job) {
+	if err := job.Run(ctx); err != nil && job.OnError != nil {
+		job.OnError(job.Name, err)
+	}
+}
+
+// wait blocks for duration and reports false if ctx was cancelled before it elapsed.
+func wait(ctx context.Context, clock clock.Clock, duration time.Duration) bool {
+	if duration <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := clock.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C():
+		return true
+	}
+}
+
+func jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
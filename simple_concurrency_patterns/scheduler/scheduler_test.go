@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"context"
+	"simple_concurrency_patterns/clock"
+	"simple_concurrency_patterns/leakcheck"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunsOnEveryTick(t *testing.T) {
+	for _, mode := range []Mode{FixedRate, FixedDelay} {
+		fake := clock.NewFake(time.Unix(0, 0))
+		jobs := NewWithClock(context.Background(), fake)
+		ran := make(chan struct{})
+		_, err := jobs.Schedule(Job{
+			Name:     "tick",
+			Interval: time.Minute,
+			Mode:     mode,
+			// Otherwise a tick arriving just before the previous run marked itself finished would be skipped.
+			AllowOverlap: true,
+			Run: func(ctx context.Context) error {
+				ran <- struct{}{}
+				return nil
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for run := 0; run < 3; run++ {
+			fake.BlockUntil(1)
+			fake.Advance(time.Minute)
+			select {
+			case <-ran:
+			case <-time.After(time.Second):
+				t.Fatalf("mode %d: run %d did not happen after advancing the clock", mode, run)
+			}
+		}
+		jobs.Stop()
+	}
+}
+
+// Stop must not return while a run is still in progress, for both modes.
+func TestStopWaitsForRunsInProgress(t *testing.T) {
+	for _, mode := range []Mode{FixedRate, FixedDelay} {
+		leaks := leakcheck.Snapshot()
+		fake := clock.NewFake(time.Unix(0, 0))
+		jobs := NewWithClock(context.Background(), fake)
+		started := make(chan struct{})
+		var finished atomic.Bool
+		_, err := jobs.Schedule(Job{
+			Name:     "slow",
+			Interval: time.Minute,
+			Mode:     mode,
+			Run: func(ctx context.Context) error {
+				close(started)
+				<-ctx.Done()
+				// Keep running a little after the cancellation, like a run cleaning up.
+				time.Sleep(20 * time.Millisecond)
+				finished.Store(true)
+				return nil
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fake.BlockUntil(1)
+		fake.Advance(time.Minute)
+		<-started
+		jobs.Stop()
+		if !finished.Load() {
+			t.Errorf("mode %d: Stop returned while a run was still in progress", mode)
+		}
+		if err := leaks.Check(time.Second); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+func TestScheduleRejectsInvalidJobs(t *testing.T) {
+	jobs := New(context.Background())
+	defer jobs.Stop()
+	if _, err := jobs.Schedule(Job{Interval: 0, Run: func(context.Context) error { return nil }}); err == nil {
+		t.Error("a job without an interval was scheduled")
+	}
+	if _, err := jobs.Schedule(Job{Interval: time.Second}); err == nil {
+		t.Error("a job without Run was scheduled")
+	}
+}
@@ -0,0 +1,139 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// A fixed number of worker go routines pull tasks from a bounded queue. Unlike spawning one go routine per item,
+// the number of go routines never exceeds the number of workers, and Submit blocks while the queue is full, so a
+// fast producer is slowed down to the speed of the workers (backpressure) instead of piling up work in memory.
+// Wait replaces the time.Sleep calls used to "hope" all the go routines finished.
+
+var ErrClosed = errors.New("workerpool: pool is closed")
+
+// Task is the unit of work, it should return early once ctx is done.
+type Task[T any] func(ctx context.Context) (T, error)
+
+type job[T any] struct {
+	index int
+	task  Task[T]
+}
+
+type Pool[T any] struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	queue  chan job[T]
+	wg     sync.WaitGroup
+
+	// submitMu serializes Submit and Wait, so the queue is never closed under a blocked Submit.
+	submitMu sync.Mutex
+	closed   bool
+
+	// mu guards the results, which workers fill in concurrently.
+	mu      sync.Mutex
+	results []T
+	errs    []error
+}
+
+// New starts the worker go routines, they exit once Wait was called and the queue is drained, so every pool must
+// be waited on. Cancelling ctx cancels the running tasks and skips the queued ones. queueSize is the number of
+// tasks which can wait for a free worker before Submit blocks.
+func New[T any](ctx context.Context, workers, queueSize int) *Pool[T] {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	pool := &Pool[T]{ctx: ctx, cancel: cancel, queue: make(chan job[T], queueSize)}
+	pool.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pool.worker()
+	}
+	return pool
+}
+
+func (pool *Pool[T]) worker() {
+	defer pool.wg.Done()
+	for job := range pool.queue {
+		var result T
+		var err error
+		// Tasks still queued when the pool is cancelled are not run, but they are reported as failed.
+		if err = pool.ctx.Err(); err == nil {
+			result, err = job.task(pool.ctx)
+		}
+
+		pool.mu.Lock()
+		pool.results[job.index] = result
+		pool.errs[job.index] = err
+		pool.mu.Unlock()
+	}
+}
+
+// Submit queues a task, blocking while the queue is full. It gives up when ctx or the pool's context is done.
+func (pool *Pool[T]) Submit(ctx context.Context, task Task[T]) error {
+	pool.submitMu.Lock()
+	defer pool.submitMu.Unlock()
+	if pool.closed {
+		return ErrClosed
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-pool.ctx.Done():
+		err = pool.ctx.Err()
+	default:
+	}
+	if err != nil {
+		return err
+	}
+
+	pool.mu.Lock()
+	index := len(pool.results)
+	var zero T
+	pool.results = append(pool.results, zero)
+	pool.errs = append(pool.errs, nil)
+	pool.mu.Unlock()
+
+	select {
+	case pool.queue <- job[T]{index: index, task: task}:
+		return nil
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-pool.ctx.Done():
+		err = pool.ctx.Err()
+	}
+	// The task was never queued, but it keeps its slot so the results stay in submission order.
+	pool.mu.Lock()
+	pool.errs[index] = err
+	pool.mu.Unlock()
+	return err
+}
+
+// Cancel stops the pool, running tasks see their context cancelled and queued tasks are skipped.
+func (pool *Pool[T]) Cancel() {
+	pool.cancel()
+}
+
+// Wait closes the pool for new submissions and waits until every worker exited. The results are in submission
+// order, and the returned error joins the errors of all the failed tasks.
+func (pool *Pool[T]) Wait() ([]T, error) {
+	pool.submitMu.Lock()
+	if !pool.closed {
+		pool.closed = true
+		close(pool.queue)
+	}
+	pool.submitMu.Unlock()
+
+	pool.wg.Wait()
+	pool.cancel()
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return pool.results, errors.Join(pool.errs...)
+}
@@ -0,0 +1,159 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"simple_concurrency_patterns/leakcheck"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestResultsInSubmissionOrder(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	pool := New[int](context.Background(), 4, 2)
+	for i := 0; i < 20; i++ {
+		i := i
+		err := pool.Submit(context.Background(), func(ctx context.Context) (int, error) {
+			// Later tasks finish first, the results must still come back in submission order.
+			time.Sleep(time.Duration(20-i) * 100 * time.Microsecond)
+			return i * i, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, err := pool.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, result := range results {
+		if result != i*i {
+			t.Fatalf("results = %v, not in submission order", results)
+		}
+	}
+
+	if err := pool.Submit(context.Background(), func(ctx context.Context) (int, error) { return 0, nil }); !errors.Is(err, ErrClosed) {
+		t.Errorf("Submit after Wait returned %v, want ErrClosed", err)
+	}
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestErrorsAreJoined(t *testing.T) {
+	pool := New[int](context.Background(), 2, 0)
+	failure := errors.New("task failed")
+	for i := 0; i < 4; i++ {
+		i := i
+		pool.Submit(context.Background(), func(ctx context.Context) (int, error) {
+			if i%2 == 1 {
+				return 0, failure
+			}
+			return i, nil
+		})
+	}
+	results, err := pool.Wait()
+	if !errors.Is(err, failure) {
+		t.Errorf("Wait returned %v, want the task error", err)
+	}
+	if !slices.Equal(results, []int{0, 0, 2, 0}) {
+		t.Errorf("results = %v", results)
+	}
+}
+
+// blockingTask runs until its context is cancelled.
+func blockingTask(started chan<- struct{}) Task[int] {
+	return func(ctx context.Context) (int, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return 0, ctx.Err()
+	}
+}
+
+func TestNoLeaksAfterCancel(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	pool := New[int](context.Background(), 3, 5)
+	started := make(chan struct{}, 8)
+	for i := 0; i < 8; i++ {
+		if err := pool.Submit(context.Background(), blockingTask(started)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Every worker is busy, the other tasks wait in the queue.
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+
+	pool.Cancel()
+	results, err := pool.Wait()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait returned %v, want context.Canceled", err)
+	}
+	if len(results) != 8 {
+		t.Errorf("got %d results, want one per submitted task", len(results))
+	}
+	if len(started) != 0 {
+		t.Errorf("%d queued tasks ran after the pool was cancelled", len(started))
+	}
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNoLeaksAfterParentContextCancelled(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := New[int](ctx, 1, 0)
+	started := make(chan struct{}, 2)
+	if err := pool.Submit(context.Background(), blockingTask(started)); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	// The only worker is busy and there is no queue, so this Submit blocks until the context is cancelled.
+	submitted := make(chan error)
+	go func() {
+		submitted <- pool.Submit(context.Background(), blockingTask(started))
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	if err := <-submitted; !errors.Is(err, context.Canceled) {
+		t.Errorf("blocked Submit returned %v, want context.Canceled", err)
+	}
+	if _, err := pool.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait returned %v, want context.Canceled", err)
+	}
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSubmitGivesUpWithItsOwnContext(t *testing.T) {
+	leaks := leakcheck.Snapshot()
+	pool := New[int](context.Background(), 1, 0)
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	pool.Submit(context.Background(), func(ctx context.Context) (int, error) {
+		started <- struct{}{}
+		<-release
+		return 1, nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.Submit(ctx, blockingTask(nil)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Submit returned %v, want context.DeadlineExceeded", err)
+	}
+
+	close(release)
+	results, err := pool.Wait()
+	if !errors.Is(err, context.DeadlineExceeded) || len(results) != 2 || results[0] != 1 {
+		t.Errorf("Wait returned %v, %v", results, err)
+	}
+	if err := leaks.Check(time.Second); err != nil {
+		t.Error(err)
+	}
+}
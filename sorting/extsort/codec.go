@@ -0,0 +1,139 @@
+package extsort
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// A Codec turns records into bytes and back. The input, the sorted runs spilled to disk and the output all use the
+// same codec.
+type Codec[T any] interface {
+	NewReader(r io.Reader) Reader[T]
+	NewWriter(w io.Writer) Writer[T]
+}
+
+// Reader returns io.EOF once there are no more records.
+type Reader[T any] interface {
+	Read() (T, error)
+}
+
+type Writer[T any] interface {
+	Write(record T) error
+	Flush() error
+}
+
+// Lines is the codec for newline separated text, every line is a record without its trailing newline.
+type Lines struct{}
+
+type lineReader struct {
+	reader *bufio.Reader
+}
+
+type lineWriter struct {
+	writer *bufio.Writer
+}
+
+func (Lines) NewReader(r io.Reader) Reader[string] {
+	return &lineReader{reader: bufio.NewReaderSize(r, 64*1024)}
+}
+
+func (Lines) NewWriter(w io.Writer) Writer[string] {
+	return &lineWriter{writer: bufio.NewWriterSize(w, 64*1024)}
+}
+
+func (reader *lineReader) Read() (string, error) {
+	line, err := reader.reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		// The last line does not need a trailing newline.
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+func (writer *lineWriter) Write(record string) error {
+	if _, err := writer.writer.WriteString(record); err != nil {
+		return err
+	}
+	return writer.writer.WriteByte('\n')
+}
+
+func (writer *lineWriter) Flush() error {
+	return writer.writer.Flush()
+}
+
+// JSONLines is the codec for one JSON document per line.
+type JSONLines[T any] struct{}
+
+type jsonReader[T any] struct {
+	decoder *json.Decoder
+}
+
+type jsonWriter[T any] struct {
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (JSONLines[T]) NewReader(r io.Reader) Reader[T] {
+	return &jsonReader[T]{decoder: json.NewDecoder(bufio.NewReaderSize(r, 64*1024))}
+}
+
+func (JSONLines[T]) NewWriter(w io.Writer) Writer[T] {
+	writer := bufio.NewWriterSize(w, 64*1024)
+	return &jsonWriter[T]{writer: writer, encoder: json.NewEncoder(writer)}
+}
+
+func (reader *jsonReader[T]) Read() (T, error) {
+	var record T
+	err := reader.decoder.Decode(&record)
+	return record, err
+}
+
+func (writer *jsonWriter[T]) Write(record T) error {
+	return writer.encoder.Encode(record)
+}
+
+func (writer *jsonWriter[T]) Flush() error {
+	return writer.writer.Flush()
+}
+
+// Gob is a compact binary codec for records of any gob encodable type, a good fit for the temporary runs.
+type Gob[T any] struct{}
+
+type gobReader[T any] struct {
+	decoder *gob.Decoder
+}
+
+type gobWriter[T any] struct {
+	writer  *bufio.Writer
+	encoder *gob.Encoder
+}
+
+func (Gob[T]) NewReader(r io.Reader) Reader[T] {
+	return &gobReader[T]{decoder: gob.NewDecoder(bufio.NewReaderSize(r, 64*1024))}
+}
+
+func (Gob[T]) NewWriter(w io.Writer) Writer[T] {
+	writer := bufio.NewWriterSize(w, 64*1024)
+	return &gobWriter[T]{writer: writer, encoder: gob.NewEncoder(writer)}
+}
+
+func (reader *gobReader[T]) Read() (T, error) {
+	var record T
+	err := reader.decoder.Decode(&record)
+	return record, err
+}
+
+func (writer *gobWriter[T]) Write(record T) error {
+	return writer.encoder.Encode(record)
+}
+
+func (writer *gobWriter[T]) Flush() error {
+	return writer.writer.Flush()
+}
@@ -0,0 +1,280 @@
+package extsort
+
+import (
+	"container/heap"
+	"errors"
+	"io"
+	"os"
+	"slices"
+)
+
+// External merge sort, for inputs which do not fit in memory:
+// 1. Read records until the memory budget is used up, sort them in memory and spill them to a temporary file as a
+//    sorted run. Repeat until the input is exhausted.
+// 2. Merge the runs: a min-heap holds the current record of every run, the smallest one is written out and
+//    replaced by the next record of its run. With more runs than MaxFanIn, groups of runs are first merged into
+//    larger runs, so the number of open files stays bounded.
+// Ties are broken by the run a record came from, and runs are sorted stably, so the whole sort is stable.
+
+type Config[T any] struct {
+	// Compare orders the records, the same kind of function as taken by slices.SortFunc.
+	Compare func(a, b T) int
+	// Codec reads the input and writes the output.
+	Codec Codec[T]
+	// RunCodec writes and reads the temporary runs, Codec is used when nil.
+	RunCodec Codec[T]
+	// Approximate number of bytes of records held in memory at once, 64 MiB by default.
+	MemoryBudget int64
+	// SizeOf estimates the memory a record takes, every record is assumed to take 64 bytes when nil.
+	SizeOf func(record T) int64
+	// Maximum number of runs merged at once, 64 by default.
+	MaxFanIn int
+	// Directory for the runs, the system's temporary directory when empty.
+	TempDir string
+}
+
+func (config *Config[T]) setDefaults() error {
+	if config.Compare == nil || config.Codec == nil {
+		return errors.New("extsort: Compare and Codec are required")
+	}
+	if config.RunCodec == nil {
+		config.RunCodec = config.Codec
+	}
+	if config.MemoryBudget <= 0 {
+		config.MemoryBudget = 64 << 20
+	}
+	if config.SizeOf == nil {
+		config.SizeOf = func(T) int64 { return 64 }
+	}
+	if config.MaxFanIn < 2 {
+		config.MaxFanIn = 64
+	}
+	return nil
+}
+
+// Sort reads every record from in and writes them to out in sorted order.
+func Sort[T any](in io.Reader, out io.Writer, config Config[T]) error {
+	if err := config.setDefaults(); err != nil {
+		return err
+	}
+
+	sorter := &sorter[T]{config: config}
+	defer sorter.cleanup()
+
+	records, err := sorter.split(config.Codec.NewReader(in))
+	if err != nil {
+		return err
+	}
+	writer := config.Codec.NewWriter(out)
+
+	// Everything fit into memory, there is nothing to merge.
+	if len(sorter.runs) == 0 {
+		for _, record := range records {
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		return writer.Flush()
+	}
+	if len(records) > 0 {
+		if err := sorter.spill(records); err != nil {
+			return err
+		}
+	}
+
+	for len(sorter.runs) > config.MaxFanIn {
+		if err := sorter.mergePass(); err != nil {
+			return err
+		}
+	}
+	if err := sorter.merge(sorter.runs, writer); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
+
+type sorter[T any] struct {
+	config Config[T]
+	// Paths of the runs waiting to be merged, in the order they were created.
+	runs []string
+	// Every file ever created, removed by cleanup.
+	files []string
+}
+
+// split reads the input, spilling a sorted run whenever the memory budget is reached. It returns the sorted
+// records of the last chunk, which are still in memory.
+func (sorter *sorter[T]) split(reader Reader[T]) ([]T, error) {
+	var records []T
+	var size int64
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+		size += sorter.config.SizeOf(record)
+
+		if size >= sorter.config.MemoryBudget {
+			if err := sorter.spill(records); err != nil {
+				return nil, err
+			}
+			// A fresh slice lets the garbage collector take the records of the spilled run.
+			records = nil
+			size = 0
+		}
+	}
+	slices.SortStableFunc(records, sorter.config.Compare)
+	return records, nil
+}
+
+func (sorter *sorter[T]) createRun() (*os.File, error) {
+	file, err := os.CreateTemp(sorter.config.TempDir, "extsort-run-*")
+	if err != nil {
+		return nil, err
+	}
+	sorter.files = append(sorter.files, file.Name())
+	return file, nil
+}
+
+func (sorter *sorter[T]) spill(records []T) error {
+	slices.SortStableFunc(records, sorter.config.Compare)
+	file, err := sorter.createRun()
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := sorter.config.RunCodec.NewWriter(file)
+	for _, record := range records {
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	sorter.runs = append(sorter.runs, file.Name())
+	return file.Close()
+}
+
+// mergePass merges the runs in groups of MaxFanIn, keeping the groups in order so the merge stays stable.
+func (sorter *sorter[T]) mergePass() error {
+	var merged []string
+	for start := 0; start < len(sorter.runs); start += sorter.config.MaxFanIn {
+		end := min(start+sorter.config.MaxFanIn, len(sorter.runs))
+		group := sorter.runs[start:end]
+		if len(group) == 1 {
+			merged = append(merged, group[0])
+			continue
+		}
+
+		file, err := sorter.createRun()
+		if err != nil {
+			return err
+		}
+		writer := sorter.config.RunCodec.NewWriter(file)
+		err = sorter.merge(group, writer)
+		if err == nil {
+			err = writer.Flush()
+		}
+		closeErr := file.Close()
+		if err != nil {
+			return err
+		}
+		if closeErr != nil {
+			return closeErr
+		}
+		merged = append(merged, file.Name())
+		for _, run := range group {
+			os.Remove(run)
+		}
+	}
+	sorter.runs = merged
+	return nil
+}
+
+func (sorter *sorter[T]) merge(runs []string, writer Writer[T]) error {
+	mergeHeap := &mergeHeap[T]{compare: sorter.config.Compare}
+	for index, run := range runs {
+		file, err := os.Open(run)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		reader := sorter.config.RunCodec.NewReader(file)
+		record, err := reader.Read()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		mergeHeap.items = append(mergeHeap.items, mergeItem[T]{record: record, run: index, reader: reader})
+	}
+	heap.Init(mergeHeap)
+
+	for mergeHeap.Len() > 0 {
+		smallest := &mergeHeap.items[0]
+		if err := writer.Write(smallest.record); err != nil {
+			return err
+		}
+		record, err := smallest.reader.Read()
+		if err == io.EOF {
+			heap.Pop(mergeHeap)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		smallest.record = record
+		heap.Fix(mergeHeap, 0)
+	}
+	return nil
+}
+
+func (sorter *sorter[T]) cleanup() {
+	for _, file := range sorter.files {
+		os.Remove(file)
+	}
+}
+
+type mergeItem[T any] struct {
+	record T
+	run    int
+	reader Reader[T]
+}
+
+// mergeHeap implements heap.Interface over the current record of every run.
+type mergeHeap[T any] struct {
+	items   []mergeItem[T]
+	compare func(a, b T) int
+}
+
+func (mergeHeap *mergeHeap[T]) Len() int {
+	return len(mergeHeap.items)
+}
+
+func (mergeHeap *mergeHeap[T]) Less(i, j int) bool {
+	if result := mergeHeap.compare(mergeHeap.items[i].record, mergeHeap.items[j].record); result != 0 {
+		return result < 0
+	}
+	return mergeHeap.items[i].run < mergeHeap.items[j].run
+}
+
+func (mergeHeap *mergeHeap[T]) Swap(i, j int) {
+	mergeHeap.items[i], mergeHeap.items[j] = mergeHeap.items[j], mergeHeap.items[i]
+}
+
+func (mergeHeap *mergeHeap[T]) Push(x any) {
+	mergeHeap.items = append(mergeHeap.items, x.(mergeItem[T]))
+}
+
+func (mergeHeap *mergeHeap[T]) Pop() any {
+	last := mergeHeap.items[len(mergeHeap.items)-1]
+	mergeHeap.items = mergeHeap.items[:len(mergeHeap.items)-1]
+	return last
+}
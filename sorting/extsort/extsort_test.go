@@ -0,0 +1,223 @@
+package extsort
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"io"
+	"math/rand"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+type item struct {
+	Key int
+	// Position in the input, to check that the sort is stable.
+	Seq int
+}
+
+func byKey(a, b item) int {
+	return cmp.Compare(a.Key, b.Key)
+}
+
+func randomItems(count, keys int) []item {
+	random := rand.New(rand.NewSource(int64(count)))
+	items := make([]item, count)
+	for i := range items {
+		items[i] = item{Key: random.Intn(keys), Seq: i}
+	}
+	return items
+}
+
+func encode[T any](t *testing.T, codec Codec[T], records []T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := codec.NewWriter(&buf)
+	for _, record := range records {
+		if err := writer.Write(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func decode[T any](t *testing.T, codec Codec[T], r io.Reader) []T {
+	t.Helper()
+	var records []T
+	reader := codec.NewReader(r)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+}
+
+// assertNoRuns fails when a run is left behind in dir.
+func assertNoRuns(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		t.Errorf("run %s was not removed", entry.Name())
+	}
+}
+
+func TestSort(t *testing.T) {
+	tests := []struct {
+		name  string
+		count int
+		// Records of 64 bytes each, the default size estimate.
+		memoryBudget int64
+		maxFanIn     int
+	}{
+		{"fits in memory", 1000, 0, 0},
+		{"single merge", 1000, 64 * 100, 0},
+		{"multi-pass merge", 1000, 64 * 30, 3},
+		{"budget smaller than a record", 50, 1, 4},
+		{"one record", 1, 1, 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			input := randomItems(test.count, test.count/10+1)
+			want := slices.Clone(input)
+			slices.SortStableFunc(want, byKey)
+
+			dir := t.TempDir()
+			var out bytes.Buffer
+			err := Sort(encode[item](t, Gob[item]{}, input), &out, Config[item]{
+				Compare:      byKey,
+				Codec:        Gob[item]{},
+				MemoryBudget: test.memoryBudget,
+				MaxFanIn:     test.maxFanIn,
+				TempDir:      dir,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := decode[item](t, Gob[item]{}, &out); !slices.Equal(got, want) {
+				t.Errorf("the output is not the stably sorted input")
+			}
+			assertNoRuns(t, dir)
+		})
+	}
+}
+
+func TestSortEmptyInput(t *testing.T) {
+	dir := t.TempDir()
+	var out bytes.Buffer
+	err := Sort(strings.NewReader(""), &out, Config[string]{Compare: strings.Compare, Codec: Lines{}, MemoryBudget: 1, TempDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("output = %q, want nothing", out.String())
+	}
+	assertNoRuns(t, dir)
+}
+
+func TestSortLines(t *testing.T) {
+	var out bytes.Buffer
+	err := Sort(strings.NewReader("pear\r\napple\nfig\napple"), &out, Config[string]{
+		Compare:      strings.Compare,
+		Codec:        Lines{},
+		MemoryBudget: 2 * 64,
+		MaxFanIn:     2,
+		TempDir:      t.TempDir(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "apple\napple\nfig\npear\n"; out.String() != want {
+		t.Errorf("output = %q, want %q", out.String(), want)
+	}
+}
+
+func TestSortRemovesRunsOnError(t *testing.T) {
+	errBroken := errors.New("broken")
+	var lines strings.Builder
+	for i := 0; i < 100; i++ {
+		lines.WriteString(strconv.Itoa(i) + "\n")
+	}
+	config := Config[string]{Compare: strings.Compare, Codec: Lines{}, MemoryBudget: 64 * 10, MaxFanIn: 3}
+
+	t.Run("reading the input", func(t *testing.T) {
+		config.TempDir = t.TempDir()
+		// The error comes after several runs were spilled.
+		input := io.MultiReader(strings.NewReader(lines.String()), iotest.ErrReader(errBroken))
+		if err := Sort(input, io.Discard, config); !errors.Is(err, errBroken) {
+			t.Fatalf("Sort = %v, want %v", err, errBroken)
+		}
+		assertNoRuns(t, config.TempDir)
+	})
+	t.Run("writing the output", func(t *testing.T) {
+		config.TempDir = t.TempDir()
+		if err := Sort(strings.NewReader(lines.String()), failingWriter{errBroken}, config); !errors.Is(err, errBroken) {
+			t.Fatalf("Sort = %v, want %v", err, errBroken)
+		}
+		assertNoRuns(t, config.TempDir)
+	})
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (writer failingWriter) Write([]byte) (int, error) {
+	return 0, writer.err
+}
+
+func TestSortRequiresCompareAndCodec(t *testing.T) {
+	if err := Sort(strings.NewReader(""), io.Discard, Config[string]{Codec: Lines{}}); err == nil {
+		t.Error("Sort without Compare did not fail")
+	}
+	if err := Sort(strings.NewReader(""), io.Discard, Config[string]{Compare: strings.Compare}); err == nil {
+		t.Error("Sort without Codec did not fail")
+	}
+}
+
+func TestGobRoundTrip(t *testing.T) {
+	type record struct {
+		Name   string
+		Values []float64
+		Nested map[string]int
+	}
+	records := []record{
+		{Name: "a", Values: []float64{1.5, -2}, Nested: map[string]int{"x": 1}},
+		{Name: ""},
+		{Name: "ünïcode", Values: []float64{0}},
+	}
+	got := decode[record](t, Gob[record]{}, encode[record](t, Gob[record]{}, records))
+	if len(got) != len(records) {
+		t.Fatalf("decoded %d records, want %d", len(got), len(records))
+	}
+	for i := range records {
+		if got[i].Name != records[i].Name || !slices.Equal(got[i].Values, records[i].Values) || len(got[i].Nested) != len(records[i].Nested) {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], records[i])
+		}
+	}
+}
+
+func TestLinesAndJSONLinesRoundTrip(t *testing.T) {
+	lines := []string{"one", "", "three with spaces"}
+	if got := decode[string](t, Lines{}, encode[string](t, Lines{}, lines)); !slices.Equal(got, lines) {
+		t.Errorf("Lines round trip = %q, want %q", got, lines)
+	}
+	items := randomItems(20, 5)
+	if got := decode[item](t, JSONLines[item]{}, encode[item](t, JSONLines[item]{}, items)); !slices.Equal(got, items) {
+		t.Errorf("JSONLines round trip = %v, want %v", got, items)
+	}
+}
@@ -6,6 +6,11 @@ import (
 	"cmp"
 	"slices"
 	"sort"
+	"strings"
+
+	"sorting/extsort"
+	"sorting/ordered"
+	"sorting/sortutil"
 )
 
 // Go's slices package implements sorting for builtins and user defined types
@@ -138,4 +143,99 @@ func main() {
 	// Sorting type defs
 	sort.Sort(myCustomPersonSlice)
 	fmt.Println(myCustomPersonSlice)
+
+	// The sortutil package composes comparators from key functions, so there is no need to write Len, Less and
+	// Swap, or a comparator function, for every combination of fields.
+	byAge := sortutil.By(func(person Person) int { return person.age })
+	byName := sortutil.By(func(person Person) string { return person.name })
+	morePersons := append(slices.Clone(myPersonSlice), Person{name: "Akshay", age: 24})
+	// Oldest first, and for the same age by name in reverse.
+	byAge.ThenBy(byName).Desc().SortStable(morePersons)
+	fmt.Println(morePersons)
+
+	// Any slice can be adapted to sort.Interface with a key function.
+	sort.Sort(sortutil.SliceBy(morePersons, func(person Person) string { return person.name }))
+	fmt.Println(morePersons)
+
+	// slices.Sort compares bytes, so "item10" sorts before "item2" and "Émile" after "Zoe". The string comparators of
+	// sortutil have the same shape as stringByLenSort and plug into the same places.
+	itemsSlice := []string{"item10", "item2", "Item1", "item02"}
+	slices.SortFunc(itemsSlice, sortutil.Natural)
+	fmt.Println(itemsSlice)
+
+	namesSlice := []string{"Zoe", "Émile", "adam", "Łukasz", "Lukas", "eve"}
+	slices.SortFunc(namesSlice, sortutil.CaseInsensitive)
+	fmt.Println(namesSlice)
+	slices.SortFunc(namesSlice, sortutil.Collate)
+	fmt.Println(namesSlice)
+
+	// Shortest first, and alphabetically for the same length.
+	sortutil.Comparator[string](stringByLenSort).ThenBy(sortutil.Collate).Sort(namesSlice)
+	fmt.Println(namesSlice)
+
+	byCollatedName := sortutil.ByFunc(func(person Person) string { return person.name }, sortutil.Collate)
+	byCollatedName.SortStable(personsSlice)
+	fmt.Println(personsSlice)
+
+	// When only the first few elements are needed there is no need to sort the whole slice.
+	fmt.Println(byAge.Desc().TopK(morePersons, 2))
+	fmt.Println(sortutil.Nth(slices.Clone(myNumbersSlice), 1, cmp.Compare[int]))
+
+	// Top keeps the most frequent words of a stream without holding all the counts in a sorted slice.
+	wordCounts := map[string]int{"go": 12, "sort": 7, "slice": 9, "map": 3, "heap": 5}
+	type wordCount struct {
+		word  string
+		count int
+	}
+	mostFrequent := sortutil.NewTop(3, sortutil.By(func(wc wordCount) int { return wc.count }).Desc())
+	for word, count := range wordCounts {
+		mostFrequent.Push(wordCount{word: word, count: count})
+	}
+	fmt.Println(mostFrequent.Items())
+
+	// An ordered map stays sorted as entries are added, instead of appending to a slice and sorting it again, and
+	// answers floor, ceiling and range queries.
+	agesByName := ordered.NewMapFunc[string, int](sortutil.Collate)
+	for _, person := range morePersons {
+		agesByName.Set(person.name, person.age)
+	}
+	agesByName.Set("Émile", 29)
+	fmt.Println(agesByName.Keys())
+	if name, age, ok := agesByName.Floor("H"); ok {
+		fmt.Println("last name before H:", name, age)
+	}
+	agesByName.Range("B", "O", func(name string, age int) bool {
+		fmt.Println(name, age)
+		return true
+	})
+
+	ages := ordered.NewSet(34, 43, 12, 24, 31, 21)
+	ages.Add(30)
+	ceiling, _ := ages.Ceiling(25)
+	fmt.Println(ages.Keys(), ceiling)
+
+	// Large slices can be sorted by several go routines at once, small ones are sorted with slices.SortFunc anyway.
+	// go test -bench . ./sortutil compares it with slices.Sort, sort.Ints and sort.Slice.
+	manyPersons := make([]Person, 0, 100000)
+	for i := 0; i < cap(manyPersons); i++ {
+		manyPersons = append(manyPersons, Person{name: fmt.Sprint("person", i), age: (i * 7919) % 100})
+	}
+	byAge.ThenBy(byName).ParallelSort(manyPersons)
+	fmt.Println(manyPersons[0], manyPersons[len(manyPersons)-1], byAge.ThenBy(byName).IsSorted(manyPersons))
+
+	// The extsort package sorts inputs larger than memory by spilling sorted runs to temporary files and merging
+	// them. The tiny memory budget forces a run every two lines.
+	input := strings.NewReader("Yuta\nGojo\nNaveen\nAkshay\nGagan\nSudhanshu\n")
+	var output strings.Builder
+	err := extsort.Sort[string](input, &output, extsort.Config[string]{
+		Compare:      strings.Compare,
+		Codec:        extsort.Lines{},
+		MemoryBudget: 2,
+		SizeOf:       func(string) int64 { return 1 },
+		MaxFanIn:     2,
+	})
+	if err != nil {
+		fmt.Println("external sort failed:", err)
+	}
+	fmt.Print(output.String())
 }
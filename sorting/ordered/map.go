@@ -0,0 +1,222 @@
+package ordered
+
+import (
+	"cmp"
+	"math/rand"
+)
+
+// Map is a skip list: a sorted linked list where every node also has a random number of "express lane" links
+// skipping over the following nodes. A node gets a link on level i+1 with probability 1/4 if it has one on level
+// i, so the top levels have few nodes and a search walks down from the top, skipping most of the list. Insert,
+// Delete, Get, Floor and Ceiling take O(log n) expected time, and iterating in key order is a walk along the bottom
+// level. Unlike keeping a sorted slice with slices.BinarySearch, an insert does not move the elements after it.
+//
+// The zero Map is not usable, create one with NewMap or NewMapFunc. A Map is not safe for concurrent use.
+type Map[K, V any] struct {
+	compare func(a, b K) int
+	head    *node[K, V] // Sentinel without a key, its links point to the first node of every level.
+	tail    *node[K, V] // Last node, nil when the map is empty.
+	level   int         // Number of levels in use.
+	length  int
+	random  *rand.Rand
+}
+
+const (
+	maxLevel = 32
+	// Probability of a node getting a link on the next level is 1/branching.
+	branching = 4
+)
+
+type node[K, V any] struct {
+	key   K
+	value V
+	next  []*node[K, V]
+	prev  *node[K, V] // Previous node on the bottom level, for iterating backwards. nil for the first node.
+}
+
+// NewMap creates a map ordered by the natural order of the keys.
+func NewMap[K cmp.Ordered, V any]() *Map[K, V] {
+	return NewMapFunc[K, V](cmp.Compare[K])
+}
+
+// NewMapFunc creates a map ordered by compare, a comparator like the ones taken by slices.SortFunc. Keys which
+// compare equal are the same key.
+func NewMapFunc[K, V any](compare func(a, b K) int) *Map[K, V] {
+	return &Map[K, V]{
+		compare: compare,
+		head:    &node[K, V]{next: make([]*node[K, V], maxLevel)},
+		level:   1,
+		random:  rand.New(rand.NewSource(rand.Int63())),
+	}
+}
+
+func (orderedMap *Map[K, V]) Len() int {
+	return orderedMap.length
+}
+
+// findPredecessors fills update with the last node before key on every level, and returns the node on the bottom
+// level after it: the first node with a key greater than or equal to key.
+func (orderedMap *Map[K, V]) findPredecessors(key K, update *[maxLevel]*node[K, V]) *node[K, V] {
+	current := orderedMap.head
+	for level := orderedMap.level - 1; level >= 0; level-- {
+		for current.next[level] != nil && orderedMap.compare(current.next[level].key, key) < 0 {
+			current = current.next[level]
+		}
+		if update != nil {
+			update[level] = current
+		}
+	}
+	return current.next[0]
+}
+
+func (orderedMap *Map[K, V]) randomLevel() int {
+	level := 1
+	for level < maxLevel && orderedMap.random.Intn(branching) == 0 {
+		level++
+	}
+	return level
+}
+
+// Set adds the key or replaces its value, it returns whether the key was already present.
+func (orderedMap *Map[K, V]) Set(key K, value V) bool {
+	var update [maxLevel]*node[K, V]
+	next := orderedMap.findPredecessors(key, &update)
+	if next != nil && orderedMap.compare(next.key, key) == 0 {
+		next.value = value
+		return true
+	}
+
+	level := orderedMap.randomLevel()
+	if level > orderedMap.level {
+		for i := orderedMap.level; i < level; i++ {
+			update[i] = orderedMap.head
+		}
+		orderedMap.level = level
+	}
+
+	inserted := &node[K, V]{key: key, value: value, next: make([]*node[K, V], level)}
+	for i := 0; i < level; i++ {
+		inserted.next[i] = update[i].next[i]
+		update[i].next[i] = inserted
+	}
+	if update[0] != orderedMap.head {
+		inserted.prev = update[0]
+	}
+	if next != nil {
+		next.prev = inserted
+	} else {
+		orderedMap.tail = inserted
+	}
+	orderedMap.length++
+	return false
+}
+
+// Get returns the value of key, and whether it is present.
+func (orderedMap *Map[K, V]) Get(key K) (V, bool) {
+	next := orderedMap.findPredecessors(key, nil)
+	if next != nil && orderedMap.compare(next.key, key) == 0 {
+		return next.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (orderedMap *Map[K, V]) Contains(key K) bool {
+	_, ok := orderedMap.Get(key)
+	return ok
+}
+
+// Delete removes the key, it returns whether the key was present.
+func (orderedMap *Map[K, V]) Delete(key K) bool {
+	var update [maxLevel]*node[K, V]
+	deleted := orderedMap.findPredecessors(key, &update)
+	if deleted == nil || orderedMap.compare(deleted.key, key) != 0 {
+		return false
+	}
+
+	for i := 0; i < len(deleted.next); i++ {
+		update[i].next[i] = deleted.next[i]
+	}
+	if deleted.next[0] != nil {
+		deleted.next[0].prev = deleted.prev
+	} else {
+		orderedMap.tail = deleted.prev
+	}
+	for orderedMap.level > 1 && orderedMap.head.next[orderedMap.level-1] == nil {
+		orderedMap.level--
+	}
+	orderedMap.length--
+	return true
+}
+
+// Min returns the smallest key and its value, ok is false when the map is empty.
+func (orderedMap *Map[K, V]) Min() (key K, value V, ok bool) {
+	return entry(orderedMap.head.next[0])
+}
+
+// Max returns the largest key and its value, ok is false when the map is empty.
+func (orderedMap *Map[K, V]) Max() (key K, value V, ok bool) {
+	return entry(orderedMap.tail)
+}
+
+// Floor returns the largest key less than or equal to key, ok is false when there is none.
+func (orderedMap *Map[K, V]) Floor(key K) (K, V, bool) {
+	next := orderedMap.findPredecessors(key, nil)
+	if next != nil && orderedMap.compare(next.key, key) == 0 {
+		return entry(next)
+	}
+	if next == nil {
+		return entry(orderedMap.tail)
+	}
+	return entry(next.prev)
+}
+
+// Ceiling returns the smallest key greater than or equal to key, ok is false when there is none.
+func (orderedMap *Map[K, V]) Ceiling(key K) (K, V, bool) {
+	return entry(orderedMap.findPredecessors(key, nil))
+}
+
+func entry[K, V any](node *node[K, V]) (key K, value V, ok bool) {
+	if node == nil {
+		return key, value, false
+	}
+	return node.key, node.value, true
+}
+
+// Ascend calls yield for every entry in increasing key order until yield returns false. The map must not be
+// modified during the iteration.
+func (orderedMap *Map[K, V]) Ascend(yield func(key K, value V) bool) {
+	for current := orderedMap.head.next[0]; current != nil; current = current.next[0] {
+		if !yield(current.key, current.value) {
+			return
+		}
+	}
+}
+
+// Descend calls yield for every entry in decreasing key order until yield returns false.
+func (orderedMap *Map[K, V]) Descend(yield func(key K, value V) bool) {
+	for current := orderedMap.tail; current != nil; current = current.prev {
+		if !yield(current.key, current.value) {
+			return
+		}
+	}
+}
+
+// Range calls yield in increasing key order for the entries with from <= key < to, until yield returns false.
+func (orderedMap *Map[K, V]) Range(from, to K, yield func(key K, value V) bool) {
+	for current := orderedMap.findPredecessors(from, nil); current != nil; current = current.next[0] {
+		if orderedMap.compare(current.key, to) >= 0 || !yield(current.key, current.value) {
+			return
+		}
+	}
+}
+
+// Keys returns all the keys in increasing order.
+func (orderedMap *Map[K, V]) Keys() []K {
+	keys := make([]K, 0, orderedMap.length)
+	orderedMap.Ascend(func(key K, _ V) bool {
+		keys = append(keys, key)
+		return true
+	})
+	return keys
+}
@@ -0,0 +1,208 @@
+package ordered
+
+import (
+	"math/rand"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// reference is the obvious implementation the skip list is checked against: a plain map and its sorted keys.
+type reference map[int]int
+
+func (ref reference) keys() []int {
+	keys := make([]int, 0, len(ref))
+	for key := range ref {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func (ref reference) floor(key int) (int, bool) {
+	keys := ref.keys()
+	i := sort.SearchInts(keys, key+1)
+	if i == 0 {
+		return 0, false
+	}
+	return keys[i-1], true
+}
+
+func (ref reference) ceiling(key int) (int, bool) {
+	keys := ref.keys()
+	i := sort.SearchInts(keys, key)
+	if i == len(keys) {
+		return 0, false
+	}
+	return keys[i], true
+}
+
+func TestMapAgainstReference(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	orderedMap := NewMap[int, int]()
+	ref := reference{}
+	// Keys from a small range, so Set replaces and Delete finds existing keys often.
+	for step := 0; step < 5000; step++ {
+		key := random.Intn(200)
+		switch operation := random.Intn(10); {
+		case operation < 5:
+			value := random.Int()
+			_, present := ref[key]
+			if replaced := orderedMap.Set(key, value); replaced != present {
+				t.Fatalf("step %d: Set(%d) = %t, want %t", step, key, replaced, present)
+			}
+			ref[key] = value
+		case operation < 8:
+			_, present := ref[key]
+			if deleted := orderedMap.Delete(key); deleted != present {
+				t.Fatalf("step %d: Delete(%d) = %t, want %t", step, key, deleted, present)
+			}
+			delete(ref, key)
+		default:
+			want, present := ref[key]
+			if value, ok := orderedMap.Get(key); ok != present || value != want {
+				t.Fatalf("step %d: Get(%d) = %d, %t, want %d, %t", step, key, value, ok, want, present)
+			}
+		}
+
+		if orderedMap.Len() != len(ref) {
+			t.Fatalf("step %d: Len() = %d, want %d", step, orderedMap.Len(), len(ref))
+		}
+		probe := random.Intn(220) - 10
+		wantFloor, wantOK := ref.floor(probe)
+		if floor, _, ok := orderedMap.Floor(probe); ok != wantOK || floor != wantFloor {
+			t.Fatalf("step %d: Floor(%d) = %d, %t, want %d, %t", step, probe, floor, ok, wantFloor, wantOK)
+		}
+		wantCeiling, wantOK := ref.ceiling(probe)
+		if ceiling, _, ok := orderedMap.Ceiling(probe); ok != wantOK || ceiling != wantCeiling {
+			t.Fatalf("step %d: Ceiling(%d) = %d, %t, want %d, %t", step, probe, ceiling, ok, wantCeiling, wantOK)
+		}
+	}
+
+	keys := ref.keys()
+	if got := orderedMap.Keys(); !slices.Equal(got, keys) {
+		t.Fatalf("Keys() = %v, want %v", got, keys)
+	}
+	var ascending, descending []int
+	orderedMap.Ascend(func(key, value int) bool {
+		if value != ref[key] {
+			t.Errorf("Ascend yielded %d for key %d, want %d", value, key, ref[key])
+		}
+		ascending = append(ascending, key)
+		return true
+	})
+	orderedMap.Descend(func(key, value int) bool {
+		descending = append(descending, key)
+		return true
+	})
+	slices.Reverse(descending)
+	if !slices.Equal(ascending, keys) || !slices.Equal(descending, keys) {
+		t.Errorf("Ascend = %v, reversed Descend = %v, want %v", ascending, descending, keys)
+	}
+}
+
+func TestEmptyMap(t *testing.T) {
+	orderedMap := NewMap[string, int]()
+	if orderedMap.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", orderedMap.Len())
+	}
+	if _, ok := orderedMap.Get("a"); ok {
+		t.Error("Get found a key in an empty map")
+	}
+	if orderedMap.Delete("a") {
+		t.Error("Delete removed a key from an empty map")
+	}
+	if _, _, ok := orderedMap.Min(); ok {
+		t.Error("Min found a key in an empty map")
+	}
+	if _, _, ok := orderedMap.Max(); ok {
+		t.Error("Max found a key in an empty map")
+	}
+	if _, _, ok := orderedMap.Floor("a"); ok {
+		t.Error("Floor found a key in an empty map")
+	}
+	if _, _, ok := orderedMap.Ceiling("a"); ok {
+		t.Error("Ceiling found a key in an empty map")
+	}
+	orderedMap.Ascend(func(string, int) bool {
+		t.Error("Ascend yielded an entry of an empty map")
+		return true
+	})
+	if keys := orderedMap.Keys(); len(keys) != 0 {
+		t.Errorf("Keys() = %v, want none", keys)
+	}
+}
+
+func TestMapEdges(t *testing.T) {
+	orderedMap := NewMap[int, string]()
+	for _, key := range []int{10, 20, 30} {
+		orderedMap.Set(key, "")
+	}
+
+	if orderedMap.Delete(15) || orderedMap.Len() != 3 {
+		t.Errorf("deleting a missing key changed the map to %v", orderedMap.Keys())
+	}
+
+	tests := []struct {
+		name      string
+		key       int
+		floor     int
+		floorOK   bool
+		ceiling   int
+		ceilingOK bool
+	}{
+		{"below the smallest key", 5, 0, false, 10, true},
+		{"the smallest key", 10, 10, true, 10, true},
+		{"between keys", 25, 20, true, 30, true},
+		{"the largest key", 30, 30, true, 30, true},
+		{"above the largest key", 35, 30, true, 0, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if floor, _, ok := orderedMap.Floor(test.key); floor != test.floor || ok != test.floorOK {
+				t.Errorf("Floor(%d) = %d, %t, want %d, %t", test.key, floor, ok, test.floor, test.floorOK)
+			}
+			if ceiling, _, ok := orderedMap.Ceiling(test.key); ceiling != test.ceiling || ok != test.ceilingOK {
+				t.Errorf("Ceiling(%d) = %d, %t, want %d, %t", test.key, ceiling, ok, test.ceiling, test.ceilingOK)
+			}
+		})
+	}
+}
+
+func TestMapRange(t *testing.T) {
+	orderedMap := NewMap[int, int]()
+	for key := 0; key < 10; key++ {
+		orderedMap.Set(key*10, key)
+	}
+	tests := []struct {
+		from, to int
+		want     []int
+	}{
+		{15, 45, []int{20, 30, 40}},
+		{20, 40, []int{20, 30}},
+		{-10, 5, []int{0}},
+		{85, 200, []int{90}},
+		{41, 49, nil},
+		{50, 20, nil},
+	}
+	for _, test := range tests {
+		var got []int
+		orderedMap.Range(test.from, test.to, func(key, value int) bool {
+			got = append(got, key)
+			return true
+		})
+		if !slices.Equal(got, test.want) {
+			t.Errorf("Range(%d, %d) = %v, want %v", test.from, test.to, got, test.want)
+		}
+	}
+
+	// Returning false stops the iteration.
+	var got []int
+	orderedMap.Ascend(func(key, value int) bool {
+		got = append(got, key)
+		return len(got) < 3
+	})
+	if !slices.Equal(got, []int{0, 10, 20}) {
+		t.Errorf("Ascend stopped after %v, want [0 10 20]", got)
+	}
+}
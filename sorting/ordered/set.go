@@ -0,0 +1,88 @@
+package ordered
+
+import "cmp"
+
+// Set is a sorted set, a Map without values.
+type Set[K any] struct {
+	entries *Map[K, struct{}]
+}
+
+// NewSet creates a set ordered by the natural order of the keys, holding the given keys.
+func NewSet[K cmp.Ordered](keys ...K) *Set[K] {
+	return NewSetFunc(cmp.Compare[K], keys...)
+}
+
+// NewSetFunc creates a set ordered by compare, holding the given keys.
+func NewSetFunc[K any](compare func(a, b K) int, keys ...K) *Set[K] {
+	set := &Set[K]{entries: NewMapFunc[K, struct{}](compare)}
+	for _, key := range keys {
+		set.Add(key)
+	}
+	return set
+}
+
+func (set *Set[K]) Len() int {
+	return set.entries.Len()
+}
+
+// Add adds the key, it returns whether the key was added, false when it was already present.
+func (set *Set[K]) Add(key K) bool {
+	return !set.entries.Set(key, struct{}{})
+}
+
+// Remove removes the key, it returns whether it was present.
+func (set *Set[K]) Remove(key K) bool {
+	return set.entries.Delete(key)
+}
+
+func (set *Set[K]) Contains(key K) bool {
+	return set.entries.Contains(key)
+}
+
+func (set *Set[K]) Min() (K, bool) {
+	key, _, ok := set.entries.Min()
+	return key, ok
+}
+
+func (set *Set[K]) Max() (K, bool) {
+	key, _, ok := set.entries.Max()
+	return key, ok
+}
+
+// Floor returns the largest key less than or equal to key.
+func (set *Set[K]) Floor(key K) (K, bool) {
+	floor, _, ok := set.entries.Floor(key)
+	return floor, ok
+}
+
+// Ceiling returns the smallest key greater than or equal to key.
+func (set *Set[K]) Ceiling(key K) (K, bool) {
+	ceiling, _, ok := set.entries.Ceiling(key)
+	return ceiling, ok
+}
+
+// Ascend calls yield for every key in increasing order until yield returns false.
+func (set *Set[K]) Ascend(yield func(key K) bool) {
+	set.entries.Ascend(func(key K, _ struct{}) bool {
+		return yield(key)
+	})
+}
+
+// Descend calls yield for every key in decreasing order until yield returns false.
+func (set *Set[K]) Descend(yield func(key K) bool) {
+	set.entries.Descend(func(key K, _ struct{}) bool {
+		return yield(key)
+	})
+}
+
+// Range calls yield in increasing order for the keys with from <= key < to, until yield returns false.
+func (set *Set[K]) Range(from, to K, yield func(key K) bool) {
+	set.entries.Range(from, to, func(key K, _ struct{}) bool {
+		return yield(key)
+	})
+}
+
+// Keys returns all the keys in increasing order.
+func (set *Set[K]) Keys() []K {
+	return set.entries.Keys()
+}
@@ -0,0 +1,60 @@
+package ordered
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	set := NewSet(5, 1, 3, 1)
+	if set.Len() != 3 || !slices.Equal(set.Keys(), []int{1, 3, 5}) {
+		t.Fatalf("NewSet kept %v, want [1 3 5]", set.Keys())
+	}
+	if set.Add(3) {
+		t.Error("Add(3) added a key which was already present")
+	}
+	if !set.Add(4) || !set.Contains(4) {
+		t.Error("Add(4) did not add the key")
+	}
+	if set.Remove(2) {
+		t.Error("Remove(2) removed a missing key")
+	}
+	if !set.Remove(1) || set.Contains(1) {
+		t.Error("Remove(1) did not remove the key")
+	}
+	if min, ok := set.Min(); !ok || min != 3 {
+		t.Errorf("Min() = %d, %t, want 3", min, ok)
+	}
+	if max, ok := set.Max(); !ok || max != 5 {
+		t.Errorf("Max() = %d, %t, want 5", max, ok)
+	}
+	if _, ok := set.Floor(2); ok {
+		t.Error("Floor(2) found a key below the smallest one")
+	}
+	if _, ok := set.Ceiling(6); ok {
+		t.Error("Ceiling(6) found a key above the largest one")
+	}
+}
+
+func TestSetFunc(t *testing.T) {
+	// A reversed comparator turns the set upside down, including Floor and Ceiling.
+	set := NewSetFunc(func(a, b int) int { return cmp.Compare(b, a) }, 1, 2, 3)
+	if !slices.Equal(set.Keys(), []int{3, 2, 1}) {
+		t.Errorf("Keys() = %v, want [3 2 1]", set.Keys())
+	}
+	if floor, ok := set.Floor(0); !ok || floor != 1 {
+		t.Errorf("Floor(0) = %d, %t, want 1", floor, ok)
+	}
+	if _, ok := set.Ceiling(0); ok {
+		t.Error("Ceiling(0) found a key past the end of the reversed order")
+	}
+
+	empty := NewSet[string]()
+	if _, ok := empty.Min(); ok {
+		t.Error("Min found a key in an empty set")
+	}
+	if empty.Remove("a") {
+		t.Error("Remove removed a key from an empty set")
+	}
+}
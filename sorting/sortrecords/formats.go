@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type record struct {
+	// The CSV fields, or the JSON line exactly as it was read, so the output is byte for byte the input reordered.
+	fields []string
+	raw    []byte
+	// The values of the sort keys, in the order of the keys.
+	keys []keyValue
+}
+
+// A format reads all the records of an input and writes the sorted records back out in the same format.
+type format interface {
+	read(input io.Reader, keys []sortKey) ([]*record, error)
+	write(output io.Writer, records []*record) error
+}
+
+// csvFormat expects a header row naming the columns unless noHeader is set, in which case the fields of the sort
+// keys are 1 based column indices.
+type csvFormat struct {
+	noHeader bool
+	header   []string
+}
+
+func (format *csvFormat) read(input io.Reader, keys []sortKey) ([]*record, error) {
+	reader := csv.NewReader(input)
+	// Rows may have different lengths, a missing column is an empty value.
+	reader.FieldsPerRecord = -1
+
+	if !format.noHeader {
+		header, err := reader.Read()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		format.header = header
+	}
+	columns, err := format.columns(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*record
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		record := &record{fields: fields, keys: make([]keyValue, len(keys))}
+		for i, key := range keys {
+			text := ""
+			if columns[i] < len(fields) {
+				text = fields[columns[i]]
+			}
+			record.keys[i] = newKeyValue(text, key.keyType)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// columns resolves the field of every key to a column index.
+func (format *csvFormat) columns(keys []sortKey) ([]int, error) {
+	columns := make([]int, len(keys))
+	for i, key := range keys {
+		if format.noHeader {
+			column, err := strconv.Atoi(key.field)
+			if err != nil || column < 1 {
+				return nil, fmt.Errorf("without a header the sort key field must be a column number, got %q", key.field)
+			}
+			columns[i] = column - 1
+			continue
+		}
+
+		columns[i] = -1
+		for column, name := range format.header {
+			if strings.TrimSpace(name) == key.field {
+				columns[i] = column
+				break
+			}
+		}
+		if columns[i] == -1 {
+			return nil, fmt.Errorf("unknown column %q, the header has %s", key.field, strings.Join(format.header, ", "))
+		}
+	}
+	return columns, nil
+}
+
+func (format *csvFormat) write(output io.Writer, records []*record) error {
+	writer := csv.NewWriter(output)
+	if format.header != nil {
+		writer.Write(format.header)
+	}
+	for _, record := range records {
+		writer.Write(record.fields)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// jsonLinesFormat reads one JSON object per line, empty lines are skipped.
+type jsonLinesFormat struct{}
+
+func (jsonLinesFormat) read(input io.Reader, keys []sortKey) ([]*record, error) {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	var records []*record
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		// Numbers are kept as written, a float64 would lose the precision of large integers.
+		decoder.UseNumber()
+		var object map[string]any
+		if err := decoder.Decode(&object); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		record := &record{raw: bytes.Clone(raw), keys: make([]keyValue, len(keys))}
+		for i, key := range keys {
+			record.keys[i] = newKeyValue(lookupPath(object, key.field), key.keyType)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// lookupPath follows a dot separated path of object fields and returns the value found as text, or an empty
+// string when the path does not exist.
+func lookupPath(object map[string]any, path string) string {
+	var value any = object
+	for _, field := range strings.Split(path, ".") {
+		nested, ok := value.(map[string]any)
+		if !ok {
+			return ""
+		}
+		if value, ok = nested[field]; !ok {
+			return ""
+		}
+	}
+
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case json.Number:
+		return value.String()
+	case bool:
+		return strconv.FormatBool(value)
+	default:
+		// Objects and arrays are compared by their JSON encoding.
+		encoded, _ := json.Marshal(value)
+		return string(encoded)
+	}
+}
+
+func (jsonLinesFormat) write(output io.Writer, records []*record) error {
+	writer := bufio.NewWriter(output)
+	for _, record := range records {
+		writer.Write(record.raw)
+		writer.WriteByte('\n')
+	}
+	return writer.Flush()
+}
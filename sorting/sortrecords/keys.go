@@ -0,0 +1,148 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sorting/sortutil"
+)
+
+// A sort key is written as field[:type][:direction], e.g. "age:numeric:desc" or "name:natural". The field is a
+// CSV column name (or its 1 based index with -no-header), or a dot separated path into a JSON object such as
+// "address.city". The type defaults to string and the direction to asc.
+
+type keyType int
+
+const (
+	stringKey keyType = iota
+	numericKey
+	naturalKey
+)
+
+var keyTypes = map[string]keyType{
+	"string":  stringKey,
+	"numeric": numericKey,
+	"natural": naturalKey,
+}
+
+type sortKey struct {
+	field      string
+	keyType    keyType
+	descending bool
+}
+
+// keyFlags collects the repeated -k flags.
+type keyFlags []sortKey
+
+func (keys *keyFlags) String() string {
+	specs := make([]string, 0, len(*keys))
+	for _, key := range *keys {
+		specs = append(specs, key.field)
+	}
+	return strings.Join(specs, ",")
+}
+
+func (keys *keyFlags) Set(spec string) error {
+	key, err := parseKey(spec)
+	if err != nil {
+		return err
+	}
+	*keys = append(*keys, key)
+	return nil
+}
+
+func parseKey(spec string) (sortKey, error) {
+	parts := strings.Split(spec, ":")
+	key := sortKey{field: parts[0]}
+	if key.field == "" {
+		return key, fmt.Errorf("sort key %q has no field", spec)
+	}
+	for _, modifier := range parts[1:] {
+		if keyType, ok := keyTypes[modifier]; ok {
+			key.keyType = keyType
+			continue
+		}
+		switch modifier {
+		case "asc":
+			key.descending = false
+		case "desc":
+			key.descending = true
+		default:
+			return key, fmt.Errorf("sort key %q: unknown modifier %q, expected numeric, string, natural, asc or desc", spec, modifier)
+		}
+	}
+	return key, nil
+}
+
+// keyValue is a field extracted from a record. Numbers are parsed once when the records are read, not on every
+// comparison.
+type keyValue struct {
+	text     string
+	number   float64
+	isNumber bool
+}
+
+func newKeyValue(text string, keyType keyType) keyValue {
+	value := keyValue{text: text}
+	if keyType == numericKey {
+		number, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		value.number, value.isNumber = number, err == nil
+	}
+	return value
+}
+
+// compareNumeric sorts the numbers by value and the values which are not numbers (including missing fields) as
+// strings. Which of the two groups comes first is up to numbersFirst.
+func compareNumeric(a, b keyValue) int {
+	if a.isNumber && b.isNumber {
+		return cmp.Compare(a.number, b.number)
+	}
+	return strings.Compare(a.text, b.text)
+}
+
+// numbersFirst puts the numbers before the values which are not numbers, in either direction.
+func numbersFirst(a, b keyValue) int {
+	switch {
+	case a.isNumber == b.isNumber:
+		return 0
+	case a.isNumber:
+		return -1
+	}
+	return 1
+}
+
+// comparator combines the sort keys into a single comparator of records, the first key decides and the following
+// ones break its ties.
+func comparator(keys []sortKey) sortutil.Comparator[*record] {
+	var combined sortutil.Comparator[*record]
+	for index, key := range keys {
+		index := index
+		var compareValues func(a, b keyValue) int
+		switch key.keyType {
+		case numericKey:
+			compareValues = compareNumeric
+		case naturalKey:
+			compareValues = func(a, b keyValue) int { return sortutil.Natural(a.text, b.text) }
+		default:
+			compareValues = func(a, b keyValue) int { return strings.Compare(a.text, b.text) }
+		}
+
+		keyOf := func(record *record) keyValue { return record.keys[index] }
+		next := sortutil.ByFunc(keyOf, compareValues)
+		if key.descending {
+			next = next.Desc()
+		}
+		if key.keyType == numericKey {
+			// Applied after the direction, so the values which are not numbers stay last in descending order too.
+			next = sortutil.ByFunc(keyOf, numbersFirst).ThenBy(next)
+		}
+		if combined == nil {
+			combined = next
+		} else {
+			combined = combined.ThenBy(next)
+		}
+	}
+	return combined
+}
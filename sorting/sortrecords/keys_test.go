@@ -0,0 +1,139 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    sortKey
+		wantErr bool
+	}{
+		{spec: "name", want: sortKey{field: "name"}},
+		{spec: "age:numeric", want: sortKey{field: "age", keyType: numericKey}},
+		{spec: "age:numeric:desc", want: sortKey{field: "age", keyType: numericKey, descending: true}},
+		{spec: "age:desc:numeric", want: sortKey{field: "age", keyType: numericKey, descending: true}},
+		{spec: "name:natural:asc", want: sortKey{field: "name", keyType: naturalKey}},
+		{spec: "name:string", want: sortKey{field: "name"}},
+		{spec: "address.city:desc", want: sortKey{field: "address.city", descending: true}},
+		// The last modifier of a kind wins.
+		{spec: "name:desc:asc", want: sortKey{field: "name"}},
+		{spec: "", wantErr: true},
+		{spec: ":numeric", wantErr: true},
+		{spec: "age:number", wantErr: true},
+		{spec: "age:DESC", wantErr: true},
+		{spec: "age:", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			key, err := parseKey(test.spec)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("parseKey(%q) = %+v, want an error", test.spec, key)
+				}
+				return
+			}
+			if err != nil || key != test.want {
+				t.Errorf("parseKey(%q) = %+v, %v, want %+v", test.spec, key, err, test.want)
+			}
+		})
+	}
+}
+
+// sortRows sorts rows of fields by the key specs and returns them joined by ",".
+func sortRows(t *testing.T, rows [][]string, specs ...string) []string {
+	t.Helper()
+	keys := make([]sortKey, len(specs))
+	for i, spec := range specs {
+		key, err := parseKey(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = key
+	}
+	records := make([]*record, len(rows))
+	for i, fields := range rows {
+		records[i] = &record{fields: fields, keys: make([]keyValue, len(keys))}
+		for j, key := range keys {
+			// The fields of the test keys are 0 based column indices.
+			column := int(key.field[0] - '0')
+			text := ""
+			if column < len(fields) {
+				text = fields[column]
+			}
+			records[i].keys[j] = newKeyValue(text, key.keyType)
+		}
+	}
+	comparator(keys).SortStable(records)
+	sorted := make([]string, len(records))
+	for i, record := range records {
+		sorted[i] = strings.Join(record.fields, ",")
+	}
+	return sorted
+}
+
+func TestNumericKeyPutsOtherValuesLast(t *testing.T) {
+	rows := [][]string{{"10"}, {"n/a"}, {"2"}, {}, {"-1.5"}, {"abc"}, {"2e1"}}
+	tests := []struct {
+		spec string
+		want []string
+	}{
+		{"0:numeric", []string{"-1.5", "2", "10", "2e1", "", "abc", "n/a"}},
+		{"0:numeric:desc", []string{"2e1", "10", "2", "-1.5", "n/a", "abc", ""}},
+	}
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			if got := sortRows(t, rows, test.spec); !slices.Equal(got, test.want) {
+				t.Errorf("sorted = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMultipleKeys(t *testing.T) {
+	rows := [][]string{
+		{"paris", "30", "file10"},
+		{"berlin", "25", "file2"},
+		{"paris", "25", "file1"},
+		{"berlin", "25", "file10"},
+		{"paris", "x", "file3"},
+		{"berlin", "40", "file1"},
+	}
+	tests := []struct {
+		name  string
+		specs []string
+		want  []string
+	}{
+		{
+			"string then numeric desc",
+			[]string{"0", "1:numeric:desc"},
+			[]string{"berlin,40,file1", "berlin,25,file2", "berlin,25,file10", "paris,30,file10", "paris,25,file1", "paris,x,file3"},
+		},
+		{
+			"string desc then numeric then natural",
+			[]string{"0:desc", "1:numeric", "2:natural"},
+			[]string{"paris,25,file1", "paris,30,file10", "paris,x,file3", "berlin,25,file2", "berlin,25,file10", "berlin,40,file1"},
+		},
+		{
+			"numeric then natural desc",
+			[]string{"1:numeric", "2:natural:desc"},
+			[]string{"berlin,25,file10", "berlin,25,file2", "paris,25,file1", "paris,30,file10", "berlin,40,file1", "paris,x,file3"},
+		},
+		{
+			// A string key orders "file10" before "file2", a natural one after it.
+			"numeric then string",
+			[]string{"1:numeric", "2"},
+			[]string{"paris,25,file1", "berlin,25,file10", "berlin,25,file2", "paris,30,file10", "berlin,40,file1", "paris,x,file3"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := sortRows(t, rows, test.specs...); !slices.Equal(got, test.want) {
+				t.Errorf("sorted =\n%q\nwant\n%q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package sortutil
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+)
+
+// Comparator has the same shape as the functions taken by slices.SortFunc: it returns a negative number when a
+// sorts before b, a positive number when a sorts after b and zero when their order does not matter. Comparators
+// compose, so instead of hand writing one per combination of fields:
+//
+//	byAgeThenName := sortutil.By(age).ThenBy(sortutil.By(name)).Desc()
+//	byAgeThenName.Sort(people)
+type Comparator[T any] func(a, b T) int
+
+// By compares values by an ordered key, e.g. By(func(p Person) int { return p.age }).
+func By[T any, K cmp.Ordered](key func(T) K) Comparator[T] {
+	return func(a, b T) int {
+		return cmp.Compare(key(a), key(b))
+	}
+}
+
+// ByFunc compares values by a key which is not ordered by itself, using compare to order the keys.
+func ByFunc[T, K any](key func(T) K, compare func(a, b K) int) Comparator[T] {
+	return func(a, b T) int {
+		return compare(key(a), key(b))
+	}
+}
+
+// ThenBy breaks the ties of comparator with next.
+func (comparator Comparator[T]) ThenBy(next Comparator[T]) Comparator[T] {
+	return func(a, b T) int {
+		if result := comparator(a, b); result != 0 {
+			return result
+		}
+		return next(a, b)
+	}
+}
+
+// Desc reverses the order of the whole comparator, including the tie breakers added before it.
+func (comparator Comparator[T]) Desc() Comparator[T] {
+	return func(a, b T) int {
+		return comparator(b, a)
+	}
+}
+
+// Sort sorts s in place. The order of equal elements is not preserved, use SortStable for that.
+func (comparator Comparator[T]) Sort(s []T) {
+	slices.SortFunc(s, comparator)
+}
+
+// SortStable sorts s in place, keeping equal elements in their original order.
+func (comparator Comparator[T]) SortStable(s []T) {
+	slices.SortStableFunc(s, comparator)
+}
+
+// SortWith sorts s, stable or not.
+func SortWith[T any](s []T, comparator Comparator[T], stable bool) {
+	if stable {
+		comparator.SortStable(s)
+		return
+	}
+	comparator.Sort(s)
+}
+
+// Less adapts the comparator to the less functions used by the sort package.
+func (comparator Comparator[T]) Less(a, b T) bool {
+	return comparator(a, b) < 0
+}
+
+// IsSorted reports whether s is sorted according to the comparator.
+func (comparator Comparator[T]) IsSorted(s []T) bool {
+	return slices.IsSortedFunc(s, comparator)
+}
+
+// Reverse returns a comparator with the opposite order, the same as comparator.Desc().
+func Reverse[T any](comparator Comparator[T]) Comparator[T] {
+	return comparator.Desc()
+}
+
+// NilsFirst lifts a comparator of values to one of pointers, with nil pointers sorting before everything else.
+func NilsFirst[T any](comparator Comparator[T]) Comparator[*T] {
+	return func(a, b *T) int {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		case b == nil:
+			return 1
+		}
+		return comparator(*a, *b)
+	}
+}
+
+// NilsLast lifts a comparator of values to one of pointers, with nil pointers sorting after everything else.
+func NilsLast[T any](comparator Comparator[T]) Comparator[*T] {
+	return func(a, b *T) int {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return 1
+		case b == nil:
+			return -1
+		}
+		return comparator(*a, *b)
+	}
+}
+
+// Slice implements sort.Interface for any slice, so it can be passed to sort.Sort, sort.Stable, heap or any other
+// code written against the interface, without writing Len, Less and Swap for every type.
+type Slice[T any] struct {
+	Items   []T
+	Compare Comparator[T]
+}
+
+var _ sort.Interface = Slice[int]{}
+
+func (slice Slice[T]) Len() int {
+	return len(slice.Items)
+}
+
+func (slice Slice[T]) Less(i, j int) bool {
+	return slice.Compare(slice.Items[i], slice.Items[j]) < 0
+}
+
+func (slice Slice[T]) Swap(i, j int) {
+	slice.Items[i], slice.Items[j] = slice.Items[j], slice.Items[i]
+}
+
+// SliceBy adapts s to sort.Interface, ordering it by key.
+func SliceBy[T any, K cmp.Ordered](s []T, key func(T) K) Slice[T] {
+	return Slice[T]{Items: s, Compare: By(key)}
+}
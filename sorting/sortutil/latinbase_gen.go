@@ -0,0 +1,87 @@
+//go:build ignore
+
+// latinbase_gen.go writes the latinBase table of the accented Latin letters, used by sortutil.Collate and by
+// strutil.Slugify in the packages module. The two modules can not import each other, so this file is the single
+// source of the table and both copies are generated from it by go generate:
+//
+//	go run latinbase_gen.go -package sortutil -o latinbase.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+)
+
+// Base letters and the lower case accented letters of Latin-1 Supplement and Latin Extended-A which reduce to
+// them.
+var table = map[string]string{
+	"a":  "àáâãäåāăą",
+	"ae": "æ",
+	"c":  "çćĉċč",
+	"d":  "ðďđ",
+	"e":  "èéêëēĕėęě",
+	"g":  "ĝğġģ",
+	"h":  "ĥħ",
+	"i":  "ìíîïĩīĭįı",
+	"ij": "ĳ",
+	"j":  "ĵ",
+	"k":  "ķ",
+	"l":  "ĺļľŀł",
+	"n":  "ñńņňŋ",
+	"o":  "òóôõöøōŏő",
+	"oe": "œ",
+	"r":  "ŕŗř",
+	"s":  "śŝşš",
+	"ss": "ß",
+	"t":  "ţťŧ",
+	"th": "þ",
+	"u":  "ùúûüũūŭůűų",
+	"w":  "ŵ",
+	"y":  "ýÿŷ",
+	"z":  "źżž",
+}
+
+func main() {
+	packageName := flag.String("package", "", "package of the generated file")
+	output := flag.String("o", "latinbase.go", "file to write")
+	flag.Parse()
+	if *packageName == "" {
+		log.Fatal("-package is required")
+	}
+
+	latinBase := make(map[rune]string)
+	for base, letters := range table {
+		for _, letter := range letters {
+			latinBase[letter] = base
+		}
+	}
+	letters := make([]rune, 0, len(latinBase))
+	for letter := range latinBase {
+		letters = append(letters, letter)
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i] < letters[j] })
+
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Code generated by latinbase_gen.go in sorting/sortutil; DO NOT EDIT.")
+	fmt.Fprintln(&buf)
+	fmt.Fprintf(&buf, "package %s\n\n", *packageName)
+	fmt.Fprintln(&buf, "// latinBase maps the lower case accented letters of Latin-1 Supplement and Latin Extended-A to their base letters.")
+	fmt.Fprintln(&buf, "var latinBase = map[rune]string{")
+	for _, letter := range letters {
+		fmt.Fprintf(&buf, "\t%q: %q,\n", letter, latinBase[letter])
+	}
+	fmt.Fprintln(&buf, "}")
+
+	source, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*output, source, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,169 @@
+package sortutil
+
+import (
+	"cmp"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// Parallel merge sort: the slice is split in halves which are sorted by separate go routines, down to pieces of
+// Threshold elements which are sorted with slices.SortFunc, and the sorted halves are merged back. The merges
+// are parallel as well: the middle element of the longer half is located in the other one by binary search,
+// which splits the merge into two independent merges. Below the threshold the overhead of go routines and of the
+// extra buffer outweighs the gain, so small slices are simply sorted on the calling go routine.
+
+const defaultParallelThreshold = 4096
+
+type ParallelOptions struct {
+	// Slices shorter than Threshold are sorted with slices.SortFunc, 4096 by default.
+	Threshold int
+	// Approximate maximum number of go routines sorting at once, runtime.GOMAXPROCS(0) by default.
+	Workers int
+	// Keep equal elements in their original order.
+	Stable bool
+}
+
+// ParallelSort sorts a slice of an ordered type in ascending order, like slices.Sort.
+func ParallelSort[S ~[]E, E cmp.Ordered](s S) {
+	// slices.Sort is specialized for ordered types and much faster than slices.SortFunc with cmp.Compare.
+	parallelSort(s, cmp.Compare[E], func(s []E) { slices.Sort(s) }, ParallelOptions{})
+}
+
+// ParallelSortFunc sorts s with compare, like slices.SortFunc. The order of equal elements is not preserved.
+func ParallelSortFunc[S ~[]E, E any](s S, compare func(a, b E) int) {
+	ParallelSortFuncWith(s, compare, ParallelOptions{})
+}
+
+// ParallelSortStableFunc sorts s with compare, like slices.SortStableFunc.
+func ParallelSortStableFunc[S ~[]E, E any](s S, compare func(a, b E) int) {
+	ParallelSortFuncWith(s, compare, ParallelOptions{Stable: true})
+}
+
+// ParallelSort sorts s in place using several go routines. The order of equal elements is not preserved.
+func (comparator Comparator[T]) ParallelSort(s []T) {
+	ParallelSortFunc(s, comparator)
+}
+
+// ParallelSortStable sorts s in place using several go routines, keeping equal elements in their original order.
+func (comparator Comparator[T]) ParallelSortStable(s []T) {
+	ParallelSortStableFunc(s, comparator)
+}
+
+func ParallelSortFuncWith[S ~[]E, E any](s S, compare func(a, b E) int, options ParallelOptions) {
+	leaf := func(s []E) { slices.SortFunc(s, compare) }
+	if options.Stable {
+		leaf = func(s []E) { slices.SortStableFunc(s, compare) }
+	}
+	parallelSort(s, compare, leaf, options)
+}
+
+// parallelSort sorts the pieces below the threshold with leaf, and merges them with compare.
+func parallelSort[E any](s []E, compare func(a, b E) int, leaf func([]E), options ParallelOptions) {
+	if options.Threshold <= 0 {
+		options.Threshold = defaultParallelThreshold
+	}
+	if options.Workers <= 0 {
+		options.Workers = runtime.GOMAXPROCS(0)
+	}
+	if len(s) <= options.Threshold || options.Workers == 1 {
+		leaf(s)
+		return
+	}
+
+	// Every level of the recursion doubles the number of go routines, so stop splitting once there are enough.
+	depth := 0
+	for 1<<depth < options.Workers {
+		depth++
+	}
+	sorter := &parallelSorter[E]{compare: compare, leaf: leaf, threshold: options.Threshold}
+	sorter.sort(s, make([]E, len(s)), depth)
+}
+
+type parallelSorter[E any] struct {
+	compare   func(a, b E) int
+	leaf      func([]E)
+	threshold int
+}
+
+// sort sorts s in place, using buffer (of the same length as s) as the space for merging.
+func (sorter *parallelSorter[E]) sort(s, buffer []E, depth int) {
+	if len(s) <= sorter.threshold || depth == 0 {
+		sorter.leaf(s)
+		return
+	}
+
+	middle := len(s) / 2
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sorter.sort(s[:middle], buffer[:middle], depth-1)
+	}()
+	sorter.sort(s[middle:], buffer[middle:], depth-1)
+	wg.Wait()
+
+	sorter.merge(s[:middle], s[middle:], buffer, depth)
+	copy(s, buffer)
+}
+
+// merge merges the sorted slices left and right into destination, taking from left first on ties so the merge
+// is stable.
+func (sorter *parallelSorter[E]) merge(left, right, destination []E, depth int) {
+	if len(left)+len(right) <= sorter.threshold || depth == 0 {
+		sorter.mergeSequential(left, right, destination)
+		return
+	}
+
+	var leftMiddle, rightMiddle int
+	if len(left) >= len(right) {
+		// Elements of right equal to the pivot go after it.
+		leftMiddle = len(left) / 2
+		pivot := left[leftMiddle]
+		rightMiddle, _ = slices.BinarySearchFunc(right, pivot, sorter.compare)
+	} else {
+		// Elements of left equal to the pivot go before it.
+		rightMiddle = len(right) / 2
+		pivot := right[rightMiddle]
+		leftMiddle = upperBound(left, pivot, sorter.compare)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sorter.merge(left[:leftMiddle], right[:rightMiddle], destination[:leftMiddle+rightMiddle], depth-1)
+	}()
+	sorter.merge(left[leftMiddle:], right[rightMiddle:], destination[leftMiddle+rightMiddle:], depth-1)
+	wg.Wait()
+}
+
+func (sorter *parallelSorter[E]) mergeSequential(left, right, destination []E) {
+	i, j, k := 0, 0, 0
+	for i < len(left) && j < len(right) {
+		if sorter.compare(right[j], left[i]) < 0 {
+			destination[k] = right[j]
+			j++
+		} else {
+			destination[k] = left[i]
+			i++
+		}
+		k++
+	}
+	k += copy(destination[k:], left[i:])
+	copy(destination[k:], right[j:])
+}
+
+// upperBound returns the index of the first element of s which sorts after target.
+func upperBound[E any](s []E, target E, compare func(a, b E) int) int {
+	low, high := 0, len(s)
+	for low < high {
+		middle := int(uint(low+high) >> 1)
+		if compare(s[middle], target) <= 0 {
+			low = middle + 1
+		} else {
+			high = middle
+		}
+	}
+	return low
+}
@@ -0,0 +1,137 @@
+package sortutil
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+// Benchmarks of ParallelSort against the standard library, on the same kinds of slices as the demo: ints and
+// Person records ordered by age.
+//
+//	go test -run '^$' -bench . ./sortutil
+
+// The same shape as the Person of the demo, which lives in package main and cannot be imported.
+type Person struct {
+	name string
+	age  int
+}
+
+func byAge(a, b Person) int {
+	return cmp.Compare(a.age, b.age)
+}
+
+var benchmarkSizes = []int{1000, 100000, 1000000}
+
+func randomInts(random *rand.Rand, size int) []int {
+	numbers := make([]int, size)
+	for i := range numbers {
+		numbers[i] = random.Int()
+	}
+	return numbers
+}
+
+func randomPersons(random *rand.Rand, size int) []Person {
+	persons := make([]Person, size)
+	for i := range persons {
+		persons[i] = Person{name: "person" + strconv.Itoa(i), age: random.Intn(100)}
+	}
+	return persons
+}
+
+// The parallel sorts are checked against the standard library, a fast wrong sort is useless.
+func TestParallelSortFuncWith(t *testing.T) {
+	for _, size := range []int{0, 1, 63, 64, 1000, 100000} {
+		t.Run(strconv.Itoa(size), func(t *testing.T) {
+			random := rand.New(rand.NewSource(2))
+			numbers := randomInts(random, size)
+			persons := randomPersons(random, size)
+
+			want := slices.Clone(numbers)
+			slices.Sort(want)
+			got := slices.Clone(numbers)
+			// A small threshold and several workers exercise the parallel path even on a single CPU.
+			options := ParallelOptions{Threshold: 64, Workers: 8}
+			ParallelSortFuncWith(got, cmp.Compare[int], options)
+			if !slices.Equal(got, want) {
+				t.Errorf("ParallelSortFuncWith of %d ints is not sorted", size)
+			}
+
+			wantPersons := slices.Clone(persons)
+			slices.SortStableFunc(wantPersons, byAge)
+			gotPersons := slices.Clone(persons)
+			options.Stable = true
+			ParallelSortFuncWith(gotPersons, byAge, options)
+			if !slices.Equal(gotPersons, wantPersons) {
+				t.Errorf("stable ParallelSortFuncWith of %d persons is not stable", size)
+			}
+		})
+	}
+}
+
+// benchmarkInts copies fresh unsorted input before every iteration, with the timer stopped, so every run sorts
+// the same random data.
+func benchmarkInts(b *testing.B, sortFunc func([]int)) {
+	for _, size := range benchmarkSizes {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			input := randomInts(rand.New(rand.NewSource(1)), size)
+			numbers := make([]int, size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				copy(numbers, input)
+				b.StartTimer()
+				sortFunc(numbers)
+			}
+		})
+	}
+}
+
+func benchmarkPersons(b *testing.B, sortFunc func([]Person)) {
+	for _, size := range benchmarkSizes {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			input := randomPersons(rand.New(rand.NewSource(1)), size)
+			persons := make([]Person, size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				copy(persons, input)
+				b.StartTimer()
+				sortFunc(persons)
+			}
+		})
+	}
+}
+
+func BenchmarkIntsSlicesSort(b *testing.B) {
+	benchmarkInts(b, func(numbers []int) { slices.Sort(numbers) })
+}
+
+func BenchmarkIntsSortInts(b *testing.B) {
+	benchmarkInts(b, sort.Ints)
+}
+
+func BenchmarkIntsParallelSort(b *testing.B) {
+	benchmarkInts(b, func(numbers []int) { ParallelSort(numbers) })
+}
+
+func BenchmarkPersonsSlicesSortFunc(b *testing.B) {
+	benchmarkPersons(b, func(persons []Person) { slices.SortFunc(persons, byAge) })
+}
+
+func BenchmarkPersonsSortSlice(b *testing.B) {
+	benchmarkPersons(b, func(persons []Person) {
+		sort.Slice(persons, func(i, j int) bool { return persons[i].age < persons[j].age })
+	})
+}
+
+func BenchmarkPersonsParallelSortFunc(b *testing.B) {
+	benchmarkPersons(b, func(persons []Person) { ParallelSortFunc(persons, byAge) })
+}
+
+func BenchmarkPersonsParallelSortStableFunc(b *testing.B) {
+	benchmarkPersons(b, func(persons []Person) { ParallelSortStableFunc(persons, byAge) })
+}
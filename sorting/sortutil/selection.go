@@ -0,0 +1,184 @@
+package sortutil
+
+import (
+	"container/heap"
+	"math/bits"
+	"slices"
+)
+
+// Selection finds the k smallest elements without sorting everything: O(n) on average for Nth and PartialSort
+// plus O(k log k) to sort the selected elements, and O(n log k) with O(k) memory for Top, which never needs the
+// whole input at once. "Smallest" is by the comparator, pass comparator.Desc() to get the largest instead.
+
+// Nth reorders s so that s[n] is the element which would be there if s was sorted, every element before it sorts
+// before or equal to it and every element after it sorts after or equal to it. It returns s[n], and panics when n
+// is out of range like indexing does.
+func Nth[S ~[]E, E any](s S, n int, compare func(a, b E) int) E {
+	_ = s[n]
+	low, high := 0, len(s)
+	// Quickselect degrades to quadratic time on unlucky pivots, so after too many rounds the remaining range is
+	// simply sorted, which bounds the worst case to O(n log n).
+	budget := 2 * bits.Len(uint(len(s)))
+	for high-low > 1 {
+		if budget == 0 {
+			slices.SortFunc(s[low:high], compare)
+			break
+		}
+		budget--
+
+		lessEnd, greaterStart := partition(s[low:high], compare)
+		lessEnd += low
+		greaterStart += low
+		switch {
+		case n < lessEnd:
+			high = lessEnd
+		case n >= greaterStart:
+			low = greaterStart
+		default:
+			// s[n] is equal to the pivot, which is already at its final place.
+			return s[n]
+		}
+	}
+	return s[n]
+}
+
+// partition splits s in three around a median of three pivot: s[:lessEnd] sorts before the pivot,
+// s[lessEnd:greaterStart] is equal to it and s[greaterStart:] sorts after it. The equal range keeps slices with
+// many duplicates from degrading the selection.
+func partition[E any](s []E, compare func(a, b E) int) (int, int) {
+	middle := len(s) / 2
+	last := len(s) - 1
+	if compare(s[middle], s[0]) < 0 {
+		s[middle], s[0] = s[0], s[middle]
+	}
+	if compare(s[last], s[middle]) < 0 {
+		s[last], s[middle] = s[middle], s[last]
+		if compare(s[middle], s[0]) < 0 {
+			s[middle], s[0] = s[0], s[middle]
+		}
+	}
+	pivot := s[middle]
+
+	lessEnd, i, greaterStart := 0, 0, len(s)
+	for i < greaterStart {
+		switch result := compare(s[i], pivot); {
+		case result < 0:
+			s[lessEnd], s[i] = s[i], s[lessEnd]
+			lessEnd++
+			i++
+		case result > 0:
+			greaterStart--
+			s[greaterStart], s[i] = s[i], s[greaterStart]
+		default:
+			i++
+		}
+	}
+	return lessEnd, greaterStart
+}
+
+// PartialSort reorders s so that s[:k] holds its k smallest elements in sorted order, the order of the rest is
+// unspecified. A k larger than len(s) sorts the whole slice.
+func PartialSort[S ~[]E, E any](s S, k int, compare func(a, b E) int) {
+	if k <= 0 {
+		return
+	}
+	if k < len(s) {
+		Nth(s, k-1, compare)
+	} else {
+		k = len(s)
+	}
+	slices.SortFunc(s[:k], compare)
+}
+
+// TopK returns the k smallest elements of s in sorted order, leaving s untouched.
+func TopK[S ~[]E, E any](s S, k int, compare func(a, b E) int) S {
+	// Partial sorting a copy is faster than pushing every element through a heap when the input is in memory.
+	clone := slices.Clone(s)
+	PartialSort(clone, k, compare)
+	k = min(max(k, 0), len(clone))
+	return clone[:k:k]
+}
+
+// Top keeps the k smallest of the elements pushed to it, for inputs which are too large to hold in memory or do
+// not end, like a stream of records or word counts:
+//
+//	oldest := sortutil.NewTop(3, byAge.Desc())
+//	for _, person := range persons {
+//		oldest.Push(person)
+//	}
+//	fmt.Println(oldest.Items())
+type Top[T any] struct {
+	k    int
+	heap topHeap[T]
+}
+
+func NewTop[T any](k int, compare func(a, b T) int) *Top[T] {
+	if k < 0 {
+		k = 0
+	}
+	return &Top[T]{k: k, heap: topHeap[T]{items: make([]T, 0, k), compare: compare}}
+}
+
+// Push offers an element, it is kept when it is among the k smallest seen so far. It returns whether it was kept.
+func (top *Top[T]) Push(item T) bool {
+	if top.heap.Len() < top.k {
+		heap.Push(&top.heap, item)
+		return true
+	}
+	// The root of the heap is the largest element kept, the first to be dropped.
+	if top.k == 0 || top.heap.compare(item, top.heap.items[0]) >= 0 {
+		return false
+	}
+	top.heap.items[0] = item
+	heap.Fix(&top.heap, 0)
+	return true
+}
+
+func (top *Top[T]) Len() int {
+	return top.heap.Len()
+}
+
+// Items returns the elements kept so far in sorted order, the Top can still be pushed to afterwards.
+func (top *Top[T]) Items() []T {
+	items := slices.Clone(top.heap.items)
+	slices.SortFunc(items, top.heap.compare)
+	return items
+}
+
+// topHeap implements heap.Interface as a max-heap, so the largest of the kept elements is at the root.
+type topHeap[T any] struct {
+	items   []T
+	compare func(a, b T) int
+}
+
+func (topHeap *topHeap[T]) Len() int {
+	return len(topHeap.items)
+}
+
+func (topHeap *topHeap[T]) Less(i, j int) bool {
+	return topHeap.compare(topHeap.items[i], topHeap.items[j]) > 0
+}
+
+func (topHeap *topHeap[T]) Swap(i, j int) {
+	topHeap.items[i], topHeap.items[j] = topHeap.items[j], topHeap.items[i]
+}
+
+func (topHeap *topHeap[T]) Push(x any) {
+	topHeap.items = append(topHeap.items, x.(T))
+}
+
+func (topHeap *topHeap[T]) Pop() any {
+	last := topHeap.items[len(topHeap.items)-1]
+	topHeap.items = topHeap.items[:len(topHeap.items)-1]
+	return last
+}
+
+// TopK returns the k smallest elements of s in sorted order, leaving s untouched.
+func (comparator Comparator[T]) TopK(s []T, k int) []T {
+	return TopK(s, k, comparator)
+}
+
+// PartialSort reorders s so that s[:k] holds its k smallest elements in sorted order.
+func (comparator Comparator[T]) PartialSort(s []T, k int) {
+	PartialSort(s, k, comparator)
+}
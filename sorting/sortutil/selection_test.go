@@ -0,0 +1,204 @@
+package sortutil
+
+import (
+	"cmp"
+	"math/rand"
+	"slices"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func sequence(n int, value func(i int) int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = value(i)
+	}
+	return s
+}
+
+var selectionInputs = []struct {
+	name string
+	s    []int
+}{
+	{"empty", nil},
+	{"single", []int{7}},
+	{"unsorted", []int{5, 3, 9, 1, 7, 2, 8}},
+	{"duplicates", []int{3, 1, 3, 3, 2, 1, 3, 2}},
+	{"all equal", sequence(100, func(int) int { return 4 })},
+	{"already sorted", sequence(1000, func(i int) int { return i })},
+	{"reverse sorted", sequence(1000, func(i int) int { return -i })},
+	{"organ pipe", sequence(1000, func(i int) int { return min(i, 1000-i) })},
+	{"few distinct values", sequence(1000, func(i int) int { return i * 7919 % 5 })},
+}
+
+func sortedCopy(s []int) []int {
+	sorted := slices.Clone(s)
+	slices.Sort(sorted)
+	return sorted
+}
+
+// kValues are the interesting k for a slice of length n, including k=0, k=len and k>len.
+func kValues(n int) []int {
+	return []int{-1, 0, 1, n / 2, n - 1, n, n + 1}
+}
+
+// checkNth verifies s[n] is the n-th smallest element and s is partitioned around it.
+func checkNth(t *testing.T, s, sorted []int, n int, got int) {
+	t.Helper()
+	if got != sorted[n] || s[n] != sorted[n] {
+		t.Fatalf("Nth(%d) = %d, s[%d] = %d, want %d", n, got, n, s[n], sorted[n])
+	}
+	for i, value := range s {
+		if (i < n && value > s[n]) || (i > n && value < s[n]) {
+			t.Fatalf("Nth(%d): s[%d] = %d is on the wrong side of %d", n, i, value, s[n])
+		}
+	}
+	if !slices.Equal(sortedCopy(s), sorted) {
+		t.Fatalf("Nth(%d) lost or duplicated elements", n)
+	}
+}
+
+func TestNth(t *testing.T) {
+	for _, input := range selectionInputs {
+		t.Run(input.name, func(t *testing.T) {
+			sorted := sortedCopy(input.s)
+			for n := range input.s {
+				// Long inputs are checked at a sample of positions, including both ends.
+				if len(input.s) > 20 && n%37 != 0 && n != len(input.s)-1 {
+					continue
+				}
+				s := slices.Clone(input.s)
+				checkNth(t, s, sorted, n, Nth(s, n, cmp.Compare[int]))
+			}
+		})
+	}
+}
+
+func TestNthOutOfRangePanics(t *testing.T) {
+	for _, n := range []int{-1, 3} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Nth(%d) of 3 elements did not panic", n)
+				}
+			}()
+			Nth([]int{1, 2, 3}, n, cmp.Compare[int])
+		}()
+	}
+}
+
+func TestPartialSort(t *testing.T) {
+	for _, input := range selectionInputs {
+		for _, k := range kValues(len(input.s)) {
+			t.Run(input.name+"/k="+strconv.Itoa(k), func(t *testing.T) {
+				sorted := sortedCopy(input.s)
+				want := sorted[:min(max(k, 0), len(sorted))]
+
+				s := slices.Clone(input.s)
+				PartialSort(s, k, cmp.Compare[int])
+				if !slices.Equal(s[:len(want)], want) {
+					t.Errorf("PartialSort(%d) s[:%d] = %v, want %v", k, len(want), s[:len(want)], want)
+				}
+				if !slices.Equal(sortedCopy(s), sorted) {
+					t.Errorf("PartialSort(%d) lost or duplicated elements", k)
+				}
+
+				original := slices.Clone(input.s)
+				if got := TopK(input.s, k, cmp.Compare[int]); !slices.Equal(got, want) {
+					t.Errorf("TopK(%d) = %v, want %v", k, got, want)
+				}
+				if !slices.Equal(input.s, original) {
+					t.Errorf("TopK(%d) modified its input", k)
+				}
+			})
+		}
+	}
+}
+
+func TestTop(t *testing.T) {
+	for _, input := range selectionInputs {
+		for _, k := range kValues(len(input.s)) {
+			t.Run(input.name+"/k="+strconv.Itoa(k), func(t *testing.T) {
+				sorted := sortedCopy(input.s)
+				want := sorted[:min(max(k, 0), len(sorted))]
+
+				top := NewTop(k, cmp.Compare[int])
+				for _, value := range input.s {
+					top.Push(value)
+				}
+				if got := top.Items(); !slices.Equal(got, want) {
+					t.Errorf("Top(%d).Items() = %v, want %v", k, got, want)
+				}
+				if top.Len() != len(want) {
+					t.Errorf("Top(%d).Len() = %d, want %d", k, top.Len(), len(want))
+				}
+			})
+		}
+	}
+}
+
+func TestTopPush(t *testing.T) {
+	top := NewTop(2, cmp.Compare[int])
+	steps := []struct {
+		value int
+		kept  bool
+	}{
+		{5, true},
+		{3, true},
+		{7, false},
+		// Equal to the largest kept element, the earlier one stays.
+		{5, false},
+		{1, true},
+	}
+	for _, step := range steps {
+		if kept := top.Push(step.value); kept != step.kept {
+			t.Errorf("Push(%d) = %t, want %t", step.value, kept, step.kept)
+		}
+	}
+	if items := top.Items(); !slices.Equal(items, []int{1, 3}) {
+		t.Errorf("Items() = %v, want [1 3]", items)
+	}
+	if NewTop(0, cmp.Compare[int]).Push(1) {
+		t.Error("a Top of 0 elements kept one")
+	}
+}
+
+// The selections are checked against sort.Slice on random persons, where many share an age, ordered by a
+// comparator with a tie breaker so the expected result is unique.
+func TestSelectionAgainstSortSlice(t *testing.T) {
+	random := rand.New(rand.NewSource(3))
+	compare := Comparator[Person](byAge).ThenBy(By(func(p Person) string { return p.name }))
+	for round := 0; round < 200; round++ {
+		persons := randomPersons(random, random.Intn(300))
+		k := random.Intn(len(persons) + 2)
+
+		want := slices.Clone(persons)
+		sort.Slice(want, func(i, j int) bool { return compare(want[i], want[j]) < 0 })
+		want = want[:min(k, len(want))]
+
+		if got := compare.TopK(persons, k); !slices.Equal(got, want) {
+			t.Fatalf("round %d: TopK(%d) differs from sort.Slice", round, k)
+		}
+		partial := slices.Clone(persons)
+		compare.PartialSort(partial, k)
+		if !slices.Equal(partial[:len(want)], want) {
+			t.Fatalf("round %d: PartialSort(%d) differs from sort.Slice", round, k)
+		}
+		top := NewTop(k, compare)
+		for _, person := range persons {
+			top.Push(person)
+		}
+		if got := top.Items(); !slices.Equal(got, want) {
+			t.Fatalf("round %d: Top(%d) differs from sort.Slice", round, k)
+		}
+		if len(persons) > 0 {
+			n := random.Intn(len(persons))
+			all := slices.Clone(persons)
+			sort.Slice(all, func(i, j int) bool { return compare(all[i], all[j]) < 0 })
+			if got := Nth(slices.Clone(persons), n, compare); got != all[n] {
+				t.Fatalf("round %d: Nth(%d) = %v, want %v", round, n, got, all[n])
+			}
+		}
+	}
+}
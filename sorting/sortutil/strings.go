@@ -0,0 +1,147 @@
+package sortutil
+
+//go:generate go run latinbase_gen.go -package sortutil -o latinbase.go
+
+import (
+	"cmp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// String comparators with the same shape as the comparator functions taken by slices.SortFunc, so they plug in
+// wherever a func(a, b string) int does, e.g. slices.SortFunc(names, sortutil.Natural) or
+// sortutil.ByFunc(func(p Person) string { return p.name }, sortutil.Collate). slices.Sort and sort.Strings
+// compare bytes, which puts "item10" before "item2", "Zoe" before "adam" and "Émile" after "Zoe".
+//
+// Every comparator falls back to comparing the bytes when the strings are equal by its own rules, so the order
+// of e.g. "a" and "A" is still deterministic and the unstable sorts always give the same result.
+
+// Natural compares runs of digits by their numeric value and everything else rune by rune, so "item2" sorts
+// before "item10". Numbers of any length are supported, they are never parsed.
+func Natural(a, b string) int {
+	// The first difference in leading zeros, only used when the strings are equal otherwise.
+	zerosTie := 0
+	for a != "" && b != "" {
+		if isDigit(a[0]) && isDigit(b[0]) {
+			numberA, restA := splitDigits(a)
+			numberB, restB := splitDigits(b)
+			if result := compareNumbers(numberA, numberB); result != 0 {
+				return result
+			}
+			if zerosTie == 0 {
+				// "01" sorts after "1".
+				zerosTie = cmp.Compare(len(numberA), len(numberB))
+			}
+			a, b = restA, restB
+			continue
+		}
+
+		runeA, sizeA := utf8.DecodeRuneInString(a)
+		runeB, sizeB := utf8.DecodeRuneInString(b)
+		if runeA != runeB {
+			return cmp.Compare(runeA, runeB)
+		}
+		a, b = a[sizeA:], b[sizeB:]
+	}
+	if result := cmp.Compare(len(a), len(b)); result != 0 {
+		return result
+	}
+	return zerosTie
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func splitDigits(s string) (string, string) {
+	end := 0
+	for end < len(s) && isDigit(s[end]) {
+		end++
+	}
+	return s[:end], s[end:]
+}
+
+// compareNumbers compares two runs of decimal digits by value.
+func compareNumbers(a, b string) int {
+	trimmedA := strings.TrimLeft(a, "0")
+	trimmedB := strings.TrimLeft(b, "0")
+	// Without leading zeros the longer number is the larger one.
+	if result := cmp.Compare(len(trimmedA), len(trimmedB)); result != 0 {
+		return result
+	}
+	return strings.Compare(trimmedA, trimmedB)
+}
+
+// CaseInsensitive compares the strings rune by rune ignoring case, like strings.EqualFold does, so "adam" sorts
+// before "Zoe". Equal strings differing only in case are ordered upper case first, as bytes are.
+func CaseInsensitive(a, b string) int {
+	x, y := a, b
+	for x != "" && y != "" {
+		runeX, sizeX := utf8.DecodeRuneInString(x)
+		runeY, sizeY := utf8.DecodeRuneInString(y)
+		if foldX, foldY := unicode.ToLower(runeX), unicode.ToLower(runeY); foldX != foldY {
+			return cmp.Compare(foldX, foldY)
+		}
+		x, y = x[sizeX:], y[sizeY:]
+	}
+	if result := cmp.Compare(len(x), len(y)); result != 0 {
+		return result
+	}
+	return strings.Compare(a, b)
+}
+
+// Collate orders strings the way people expect names in a dictionary or phone book to be ordered, a simplified
+// version of the Unicode Collation Algorithm in three levels:
+//  1. The base letters, ignoring accents and case: "Émile" sorts between "adam" and "Zoe", and "Ærø" is compared
+//     as "aero".
+//  2. When the base letters are equal, unaccented letters sort before accented ones: "resume" < "résumé".
+//  3. When the accents are equal too, lower case sorts before upper case: "polish" < "Polish".
+//
+// Only the accented Latin letters are decomposed into base letters, all other scripts are compared by their code
+// points. Full, language specific collation needs the tables of golang.org/x/text/collate.
+func Collate(a, b string) int {
+	keyA, keyB := collationKey(a), collationKey(b)
+	for level := 0; level < 3; level++ {
+		if result := compareWeights(keyA, keyB, level); result != 0 {
+			return result
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// collationElement holds the weights of a single base letter.
+type collationElement struct {
+	// Primary is the lower case base letter, secondary the accented letter it came from (0 when there was no
+	// accent) and tertiary 1 for upper case letters.
+	weights [3]rune
+}
+
+func collationKey(s string) []collationElement {
+	key := make([]collationElement, 0, len(s))
+	for _, r := range s {
+		tertiary := rune(0)
+		if unicode.IsUpper(r) {
+			tertiary = 1
+		}
+		lower := unicode.ToLower(r)
+		base, accented := latinBase[lower]
+		if !accented {
+			key = append(key, collationElement{weights: [3]rune{lower, 0, tertiary}})
+			continue
+		}
+		for _, baseRune := range base {
+			key = append(key, collationElement{weights: [3]rune{baseRune, lower, tertiary}})
+		}
+	}
+	return key
+}
+
+func compareWeights(a, b []collationElement, level int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i].weights[level] != b[i].weights[level] {
+			return cmp.Compare(a[i].weights[level], b[i].weights[level])
+		}
+	}
+	return cmp.Compare(len(a), len(b))
+}
@@ -0,0 +1,135 @@
+package sortutil
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func sign(result int) int {
+	switch {
+	case result < 0:
+		return -1
+	case result > 0:
+		return 1
+	}
+	return 0
+}
+
+type comparison struct {
+	a, b string
+	want int
+}
+
+func testComparator(t *testing.T, name string, compare func(a, b string) int, tests []comparison) {
+	t.Helper()
+	for _, test := range tests {
+		if got := sign(compare(test.a, test.b)); got != test.want {
+			t.Errorf("%s(%q, %q) = %d, want %d", name, test.a, test.b, got, test.want)
+		}
+		if got := sign(compare(test.b, test.a)); got != -test.want {
+			t.Errorf("%s(%q, %q) = %d, want %d", name, test.b, test.a, got, -test.want)
+		}
+	}
+}
+
+func TestNatural(t *testing.T) {
+	testComparator(t, "Natural", Natural, []comparison{
+		{"", "", 0},
+		{"", "a", -1},
+		{"item2", "item10", -1},
+		{"item10", "item10", 0},
+		{"a1b2", "a1b10", -1},
+		{"2", "a", -1},
+		// Leading zeros only decide between strings which are equal otherwise, the longer number sorts last.
+		{"a01", "a1", 1},
+		{"a001", "a01", 1},
+		{"a01b", "a1c", -1},
+		// The first difference in leading zeros decides.
+		{"a1b01", "a01b1", -1},
+		{"0", "00", -1},
+		// Digit runs longer than any integer type.
+		{"x123456789012345678901234567890", "x99", 1},
+		{"x123456789012345678901234567890", "x123456789012345678901234567891", -1},
+		{"x0000000000000000000000000000001", "x2", -1},
+		{"v1.10.0", "v1.9.0", 1},
+		{"Item2", "item1", -1},
+		{"é1", "e2", 1},
+	})
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	testComparator(t, "CaseInsensitive", CaseInsensitive, []comparison{
+		{"adam", "Zoe", -1},
+		{"ADAM", "adam", -1},
+		{"a", "A", 1},
+		{"abc", "ABD", -1},
+		{"ab", "ABC", -1},
+		{"Émile", "émile", -1},
+		{"émile", "Zoe", 1},
+		{"ǅ", "ǆ", -1},
+		{"", "a", -1},
+	})
+}
+
+func TestCollate(t *testing.T) {
+	testComparator(t, "Collate", Collate, []comparison{
+		{"adam", "Émile", -1},
+		{"Émile", "Zoe", -1},
+		{"resume", "résumé", -1},
+		{"résume", "résumé", -1},
+		{"résumé", "resumes", -1},
+		{"polish", "Polish", -1},
+		{"Polish", "pólish", -1},
+		{"aero", "Ærø", -1},
+		{"Ærø", "aerz", -1},
+		{"strasse", "straße", -1},
+		{"straße", "strassf", -1},
+		{"ﬁ", "fi", 1},
+		{"a", "a", 0},
+		{"Zoe", "zoe", 1},
+	})
+}
+
+// corpus mixes the cases the comparators treat specially.
+var corpus = []string{
+	"", "a", "A", "a1", "a01", "a001", "a10", "a2", "A2", "item10", "item9", "x99", "x123456789012345678901234567890",
+	"adam", "Adam", "ADAM", "émile", "Émile", "emile", "Zoe", "zoe", "résumé", "resume", "Résumé", "straße", "strasse",
+	"Ærø", "aero", "ǅ", "ǆ", "日本", "日本語", "1", "01", "001", "10",
+}
+
+// The comparators must be antisymmetric and agree with themselves, or the sorts give inconsistent results.
+func TestComparatorsAreConsistent(t *testing.T) {
+	comparators := map[string]func(a, b string) int{
+		"Natural":         Natural,
+		"CaseInsensitive": CaseInsensitive,
+		"Collate":         Collate,
+	}
+	for name, compare := range comparators {
+		t.Run(name, func(t *testing.T) {
+			for _, a := range corpus {
+				for _, b := range corpus {
+					ab, ba := sign(compare(a, b)), sign(compare(b, a))
+					if ab != -ba {
+						t.Errorf("%s(%q, %q) = %d but %s(%q, %q) = %d", name, a, b, ab, name, b, a, ba)
+					}
+					// Strings are only equal when they are the same, thanks to the byte fallback.
+					if (ab == 0) != (a == b) {
+						t.Errorf("%s(%q, %q) = %d", name, a, b, ab)
+					}
+					for _, c := range corpus {
+						if ab < 0 && sign(compare(b, c)) < 0 && sign(compare(a, c)) >= 0 {
+							t.Errorf("%s is not transitive: %q < %q < %q but not %q < %q", name, a, b, c, a, c)
+						}
+					}
+				}
+			}
+
+			sorted := slices.Clone(corpus)
+			slices.SortFunc(sorted, compare)
+			if !slices.IsSortedFunc(sorted, compare) {
+				t.Errorf("the sorted corpus is not sorted: %q", strings.Join(sorted, " "))
+			}
+		})
+	}
+}